@@ -1,15 +1,22 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/SaeedAlian/megavault/api/config"
 	"github.com/SaeedAlian/megavault/api/services/blog"
+	"github.com/SaeedAlian/megavault/api/services/comment"
+	"github.com/SaeedAlian/megavault/api/services/mail"
 	"github.com/SaeedAlian/megavault/api/services/user"
 )
 
@@ -25,25 +32,98 @@ func NewServer(addr string, db *sql.DB) *Server {
 	}
 }
 
+// Run serves the API until the process is interrupted, then stops accepting
+// requests and writes the views that are still buffered before returning.
 func (s *Server) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	trustedProxies, err := blog.ParseTrustedProxies(config.Env.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
 	router := mux.NewRouter()
 	subrouter := router.PathPrefix("/api/v1").Subrouter()
 
 	userSubrouter := subrouter.PathPrefix("/user").Subrouter()
 	blogSubrouter := subrouter.PathPrefix("/blog").Subrouter()
+	commentSubrouter := subrouter.PathPrefix("/comment").Subrouter()
 
 	blogMdFileUploadDir := fmt.Sprintf("%s/blogs/mds", config.Env.UploadsRootDir)
 	blogImageUploadDir := fmt.Sprintf("%s/blogs/images", config.Env.UploadsRootDir)
 
+	mailer := mail.NewMailer()
+
 	userStore := user.NewStore(s.db)
-	userService := user.NewHandler(userStore)
+	userService := user.NewHandler(userStore, mailer)
 	userService.RegisterRoutes(userSubrouter)
 
 	blogStore := blog.NewStore(s.db)
-	blogService := blog.NewHandler(blogStore, userStore, blogMdFileUploadDir, blogImageUploadDir)
+	blogViewCounter := blog.NewViewCounter(blogStore, 30*time.Second, trustedProxies)
+
+	// The views are flushed after the server has shut down, so they get their
+	// own context instead of the one cancelled by the signal.
+	viewsCtx, stopViews := context.WithCancel(context.Background())
+	viewsDone := make(chan struct{})
+	go func() {
+		blogViewCounter.Run(viewsCtx)
+		close(viewsDone)
+	}()
+	defer func() {
+		stopViews()
+		<-viewsDone
+	}()
+
+	blogService := blog.NewHandler(
+		blogStore,
+		userStore,
+		blogMdFileUploadDir,
+		blogImageUploadDir,
+		blogViewCounter,
+	)
 	blogService.RegisterRoutes(blogSubrouter)
+	blogService.RegisterSiteRoutes(router)
+
+	commentStore := comment.NewStore(s.db)
+	commentService := comment.NewHandler(commentStore, blogStore, userStore)
+	commentService.RegisterRoutes(commentSubrouter)
+
+	blogScheduler := blogService.NewScheduler(time.Minute)
+	go blogScheduler.Run(ctx)
+
+	if config.Env.UploadsGCIntervalMinutes > 0 {
+		blogVault := blog.NewVault(
+			blogStore,
+			blogMdFileUploadDir,
+			blogImageUploadDir,
+			time.Duration(config.Env.UploadsGCGraceHours)*time.Hour,
+			config.Env.UploadsGCDryRun,
+		)
+		go blogVault.Run(
+			ctx,
+			time.Duration(config.Env.UploadsGCIntervalMinutes)*time.Minute,
+		)
+	}
+
+	server := &http.Server{Addr: s.addr, Handler: router}
+	serverErr := make(chan error, 1)
+
+	go func() {
+		log.Println("API Listening on ", s.addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down the API")
 
-	log.Println("API Listening on ", s.addr)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	return http.ListenAndServe(s.addr, router)
+	return server.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/services/blog"
+)
+
+func runBackfillStats(db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("backfill-stats", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "compute the stats without saving them")
+	flags.Parse(args)
+
+	updated, skipped, err := blog.BackfillReadingStats(
+		blog.NewStore(db),
+		fmt.Sprintf("%s/blogs/mds", config.Env.UploadsRootDir),
+		*dryRun,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range skipped {
+		fmt.Printf("skipped  %s (blog %s, %s)\n", b.MDFilename, b.BlogId, b.Slug)
+	}
+
+	fmt.Printf("updated the reading stats of %d blogs, %d skipped", updated, len(skipped))
+	if *dryRun {
+		fmt.Print(" (dry run)")
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -17,6 +17,19 @@ type Config struct {
 	DBPort         string
 	JWTSecret      string
 	UploadsRootDir string
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUser       string
+	SMTPPassword   string
+	MailFrom       string
+	TrustedProxies string
+
+	UsernameChangeCooldownDays int64
+	UsernameReservationDays    int64
+	CommentEditWindowMinutes   int64
+	UploadsGCIntervalMinutes   int64
+	UploadsGCGraceHours        int64
+	UploadsGCDryRun            bool
 }
 
 var Env = InitConfig()
@@ -34,6 +47,19 @@ func InitConfig() Config {
 		DBPort:         getEnv("DB_PORT", "5432"),
 		JWTSecret:      getEnv("JWT_SECRET", "secret"),
 		UploadsRootDir: getEnv("UPLOADS_ROOT_DIR", "uploads"),
+		SMTPHost:       getEnv("SMTP_HOST", ""),
+		SMTPPort:       getEnv("SMTP_PORT", "587"),
+		SMTPUser:       getEnv("SMTP_USER", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+		MailFrom:       getEnv("MAIL_FROM", "no-reply@megavault.local"),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+
+		UsernameChangeCooldownDays: getEnvAsInt("USERNAME_CHANGE_COOLDOWN_DAYS", 30),
+		UsernameReservationDays:    getEnvAsInt("USERNAME_RESERVATION_DAYS", 90),
+		CommentEditWindowMinutes:   getEnvAsInt("COMMENT_EDIT_WINDOW_MINUTES", 15),
+		UploadsGCIntervalMinutes:   getEnvAsInt("UPLOADS_GC_INTERVAL_MINUTES", 60),
+		UploadsGCGraceHours:        getEnvAsInt("UPLOADS_GC_GRACE_HOURS", 24),
+		UploadsGCDryRun:            getEnvAsBool("UPLOADS_GC_DRY_RUN", true),
 	}
 }
 
@@ -57,3 +83,16 @@ func getEnvAsInt(key string, fallback int64) int64 {
 
 	return fallback
 }
+
+func getEnvAsBool(key string, fallback bool) bool {
+	if val, ok := os.LookupEnv(key); ok {
+		v, err := strconv.ParseBool(val)
+		if err != nil {
+			return fallback
+		}
+
+		return v
+	}
+
+	return fallback
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS users_createdat_id_idx;
+DROP INDEX IF EXISTS users_email_trgm_idx;
+DROP INDEX IF EXISTS users_lastname_trgm_idx;
+DROP INDEX IF EXISTS users_firstname_trgm_idx;
+DROP INDEX IF EXISTS users_username_trgm_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,7 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(31) NOT NULL DEFAULT 'user';
+CREATE INDEX IF NOT EXISTS users_username_trgm_idx ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_firstname_trgm_idx ON users USING GIN (firstname gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_lastname_trgm_idx ON users USING GIN (lastname gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS users_createdat_id_idx ON users (createdAt, id);
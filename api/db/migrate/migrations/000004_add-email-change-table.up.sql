@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS email_changes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  oldEmail VARCHAR(255) NOT NULL,
+  newEmail VARCHAR(255) NOT NULL,
+  tokenHash VARCHAR(64) NOT NULL UNIQUE,
+  undoTokenHash VARCHAR(64) NOT NULL UNIQUE,
+  expiresAt TIMESTAMP NOT NULL,
+  undoExpiresAt TIMESTAMP NOT NULL,
+  confirmedAt TIMESTAMP,
+  revertedAt TIMESTAMP,
+  createdAt TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS username_history (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  username VARCHAR(255) NOT NULL,
+  changedAt TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS username_history_username_idx ON username_history (username, changedAt);
+CREATE INDEX IF NOT EXISTS username_history_userid_idx ON username_history (userId, changedAt);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS user_preferences (
+  userId UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  version INTEGER NOT NULL,
+  preferences JSONB NOT NULL DEFAULT '{}',
+  updatedAt TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS follows;
+DROP INDEX IF EXISTS blogs_authorid_createdat_idx;
+ALTER TABLE blogs DROP COLUMN IF EXISTS authorId;
@@ -0,0 +1,10 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS authorId UUID REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS blogs_authorid_createdat_idx ON blogs (authorId, createdAt, id);
+CREATE TABLE IF NOT EXISTS follows (
+  followerId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  followeeId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  createdAt TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (followerId, followeeId),
+  CHECK (followerId <> followeeId)
+);
+CREATE INDEX IF NOT EXISTS follows_followeeid_idx ON follows (followeeId);
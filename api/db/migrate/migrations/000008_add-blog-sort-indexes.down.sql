@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS blogs_title_id_idx;
+DROP INDEX IF EXISTS blogs_updatedat_id_idx;
+DROP INDEX IF EXISTS blogs_createdat_id_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS blogs_createdat_id_idx ON blogs (createdAt, id);
+CREATE INDEX IF NOT EXISTS blogs_updatedat_id_idx ON blogs (updatedAt, id);
+CREATE INDEX IF NOT EXISTS blogs_title_id_idx ON blogs (title, id);
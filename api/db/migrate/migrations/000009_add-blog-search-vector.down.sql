@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS blogs_searchvector_idx;
+ALTER TABLE blogs DROP COLUMN IF EXISTS searchVector;
@@ -0,0 +1,3 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS searchVector TSVECTOR;
+UPDATE blogs SET searchVector = setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(description, '')), 'B');
+CREATE INDEX IF NOT EXISTS blogs_searchvector_idx ON blogs USING GIN (searchVector);
@@ -0,0 +1 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS visibility VARCHAR(31) NOT NULL DEFAULT 'public';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS blogs_status_expiresat_idx;
+DROP INDEX IF EXISTS blogs_status_scheduledat_idx;
+ALTER TABLE blogs DROP COLUMN IF EXISTS expiresAt;
+ALTER TABLE blogs DROP COLUMN IF EXISTS scheduledAt;
+ALTER TABLE blogs DROP COLUMN IF EXISTS publishedAt;
+ALTER TABLE blogs DROP COLUMN IF EXISTS status;
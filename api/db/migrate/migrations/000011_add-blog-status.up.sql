@@ -0,0 +1,7 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS status VARCHAR(31) NOT NULL DEFAULT 'draft';
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS publishedAt TIMESTAMP;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS scheduledAt TIMESTAMP;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS expiresAt TIMESTAMP;
+UPDATE blogs SET status = 'published', publishedAt = createdAt;
+CREATE INDEX IF NOT EXISTS blogs_status_scheduledat_idx ON blogs (status, scheduledAt);
+CREATE INDEX IF NOT EXISTS blogs_status_expiresat_idx ON blogs (status, expiresAt);
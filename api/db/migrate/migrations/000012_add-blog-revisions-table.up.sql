@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS blog_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  revision INTEGER NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description VARCHAR(2047) NOT NULL,
+  slug VARCHAR(255) NOT NULL,
+  pictureName VARCHAR(255) NOT NULL,
+  mdFilename VARCHAR(255) NOT NULL,
+  content TEXT NOT NULL DEFAULT '',
+  editorId UUID REFERENCES users(id) ON DELETE SET NULL,
+  createdAt TIMESTAMP DEFAULT NOW(),
+  UNIQUE (blogId, revision)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS blog_tags;
+DROP TABLE IF EXISTS tags;
+DROP INDEX IF EXISTS blogs_categoryid_idx;
+ALTER TABLE blogs DROP COLUMN IF EXISTS categoryId;
+DROP TABLE IF EXISTS categories;
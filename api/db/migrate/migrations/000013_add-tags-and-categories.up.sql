@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS categories (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name VARCHAR(255) NOT NULL,
+  slug VARCHAR(255) NOT NULL UNIQUE,
+  parentId UUID REFERENCES categories(id) ON DELETE SET NULL,
+  createdAt TIMESTAMP DEFAULT NOW(),
+  CHECK (parentId <> id)
+);
+CREATE INDEX IF NOT EXISTS categories_parentid_idx ON categories (parentId);
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS categoryId UUID REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS blogs_categoryid_idx ON blogs (categoryId);
+CREATE TABLE IF NOT EXISTS tags (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name VARCHAR(63) NOT NULL,
+  slug VARCHAR(63) NOT NULL UNIQUE,
+  createdAt TIMESTAMP DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS blog_tags (
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  tagId UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (blogId, tagId)
+);
+CREATE INDEX IF NOT EXISTS blog_tags_tagid_idx ON blog_tags (tagId);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS comments;
+ALTER TABLE blogs DROP COLUMN IF EXISTS commentsEnabled;
@@ -0,0 +1,15 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS commentsEnabled BOOLEAN NOT NULL DEFAULT TRUE;
+CREATE TABLE IF NOT EXISTS comments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  parentId UUID REFERENCES comments(id) ON DELETE CASCADE,
+  authorId UUID REFERENCES users(id) ON DELETE SET NULL,
+  body TEXT NOT NULL,
+  status VARCHAR(31) NOT NULL DEFAULT 'pending',
+  createdAt TIMESTAMP DEFAULT NOW(),
+  editedAt TIMESTAMP,
+  deletedAt TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS comments_blogid_createdat_idx ON comments (blogId, createdAt);
+CREATE INDEX IF NOT EXISTS comments_status_createdat_idx ON comments (status, createdAt);
+CREATE INDEX IF NOT EXISTS comments_authorid_status_idx ON comments (authorId, status);
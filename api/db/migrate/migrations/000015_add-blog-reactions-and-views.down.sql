@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS blog_views;
+DROP TABLE IF EXISTS blog_reactions;
+ALTER TABLE blogs DROP COLUMN IF EXISTS viewCount;
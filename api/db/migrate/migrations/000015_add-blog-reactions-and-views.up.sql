@@ -0,0 +1,15 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS viewCount BIGINT NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS blog_reactions (
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  reaction VARCHAR(31) NOT NULL,
+  createdAt TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (blogId, userId, reaction)
+);
+CREATE TABLE IF NOT EXISTS blog_views (
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  visitorId VARCHAR(255) NOT NULL,
+  day DATE NOT NULL,
+  PRIMARY KEY (blogId, visitorId, day)
+);
+CREATE INDEX IF NOT EXISTS blog_views_day_idx ON blog_views (day);
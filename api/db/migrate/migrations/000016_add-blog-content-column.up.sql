@@ -0,0 +1,2 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS content TEXT;
+UPDATE blogs SET content = r.content FROM blog_revisions r WHERE r.blogId = blogs.id AND r.revision = (SELECT MAX(revision) FROM blog_revisions WHERE blogId = blogs.id) AND blogs.content IS NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS slug_history (
+  slug VARCHAR(255) PRIMARY KEY,
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  createdAt TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS slug_history_blog_idx ON slug_history (blogId);
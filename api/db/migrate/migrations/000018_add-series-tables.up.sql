@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS series (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(255) NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  authorId UUID REFERENCES users(id) ON DELETE SET NULL,
+  createdAt TIMESTAMP DEFAULT NOW(),
+  updatedAt TIMESTAMP DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS series_authorid_idx ON series (authorId);
+CREATE TABLE IF NOT EXISTS series_blogs (
+  seriesId UUID NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+  blogId UUID NOT NULL UNIQUE REFERENCES blogs(id) ON DELETE CASCADE,
+  position INT NOT NULL CHECK (position > 0),
+  PRIMARY KEY (seriesId, blogId),
+  UNIQUE (seriesId, position) DEFERRABLE INITIALLY DEFERRED
+);
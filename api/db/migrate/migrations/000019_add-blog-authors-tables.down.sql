@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS blog_invitations;
+DROP TABLE IF EXISTS blog_authors;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS blog_authors (
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  userId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(15) NOT NULL CHECK (role IN ('owner', 'coauthor', 'reviewer')),
+  createdAt TIMESTAMP DEFAULT NOW(),
+  PRIMARY KEY (blogId, userId)
+);
+CREATE INDEX IF NOT EXISTS blog_authors_userid_idx ON blog_authors (userId);
+INSERT INTO blog_authors (blogId,userId,role) SELECT id, authorId, 'owner' FROM blogs WHERE authorId IS NOT NULL ON CONFLICT DO NOTHING;
+CREATE TABLE IF NOT EXISTS blog_invitations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  blogId UUID NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+  inviterId UUID REFERENCES users(id) ON DELETE SET NULL,
+  inviteeId UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(15) NOT NULL CHECK (role IN ('coauthor', 'reviewer')),
+  status VARCHAR(15) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+  createdAt TIMESTAMP DEFAULT NOW(),
+  respondedAt TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS blog_invitations_pending_idx ON blog_invitations (blogId, inviteeId) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS blog_invitations_inviteeid_idx ON blog_invitations (inviteeId, status);
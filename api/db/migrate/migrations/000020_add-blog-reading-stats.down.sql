@@ -0,0 +1,5 @@
+ALTER TABLE blogs DROP COLUMN IF EXISTS codeBlockCount;
+ALTER TABLE blogs DROP COLUMN IF EXISTS imageCount;
+ALTER TABLE blogs DROP COLUMN IF EXISTS headingCount;
+ALTER TABLE blogs DROP COLUMN IF EXISTS readingTime;
+ALTER TABLE blogs DROP COLUMN IF EXISTS wordCount;
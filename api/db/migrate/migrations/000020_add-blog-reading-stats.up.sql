@@ -0,0 +1,5 @@
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS wordCount INT NOT NULL DEFAULT 0;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS readingTime INT NOT NULL DEFAULT 0;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS headingCount INT NOT NULL DEFAULT 0;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS imageCount INT NOT NULL DEFAULT 0;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS codeBlockCount INT NOT NULL DEFAULT 0;
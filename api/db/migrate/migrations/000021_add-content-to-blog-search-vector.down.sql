@@ -0,0 +1 @@
+UPDATE blogs SET searchVector = setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(description, '')), 'B');
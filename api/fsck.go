@@ -0,0 +1,87 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/services/blog"
+)
+
+func runFsck(db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("fsck", flag.ExitOnError)
+	dryRun := flags.Bool(
+		"dry-run",
+		config.Env.UploadsGCDryRun,
+		"report orphan files without removing them",
+	)
+	grace := flags.Duration(
+		"grace",
+		time.Duration(config.Env.UploadsGCGraceHours)*time.Hour,
+		"keep orphan files younger than this",
+	)
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Parse(args)
+
+	vault := blog.NewVault(
+		blog.NewStore(db),
+		fmt.Sprintf("%s/blogs/mds", config.Env.UploadsRootDir),
+		fmt.Sprintf("%s/blogs/images", config.Env.UploadsRootDir),
+		*grace,
+		*dryRun,
+	)
+
+	report, err := vault.Check(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	for _, f := range report.MissingFiles {
+		fmt.Printf("missing  %-8s %s (blog %s, %s)\n", f.Kind, f.Filename, f.BlogId, f.Slug)
+	}
+
+	for _, f := range report.OrphanFiles {
+		state := "orphan"
+		switch {
+		case f.Pending:
+			state = "pending"
+		case f.Removed:
+			state = "removed"
+		}
+
+		fmt.Printf(
+			"%-8s %s (%d bytes, modified %s)\n",
+			state,
+			f.Path,
+			f.Size,
+			f.ModifiedAt.Format(time.RFC3339),
+		)
+	}
+
+	fmt.Printf(
+		"\nchecked %d blogs: %d missing files, %d orphan files (%d in grace period), "+
+			"%d removed, %d bytes reclaimed",
+		report.CheckedBlogs,
+		len(report.MissingFiles),
+		len(report.OrphanFiles),
+		report.PendingFiles,
+		report.RemovedFiles,
+		report.ReclaimedSize,
+	)
+	if report.DryRun {
+		fmt.Print(" (dry run)")
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/services/blog"
+	"github.com/SaeedAlian/megavault/api/services/user"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+)
+
+func runImport(db *sql.DB, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without creating blogs")
+	author := flags.String("author", "", "username of the author of the imported blogs")
+	uploads := flags.String("uploads", "", "wp-content/uploads directory of a WordPress export")
+	cover := flags.String("cover", "", "image used as the cover of posts without images")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import [flags] <site directory | export.xml>")
+	}
+
+	if *author == "" {
+		return fmt.Errorf("the -author flag is required")
+	}
+
+	u, err := user.NewStore(db).GetUserByUsername(*author)
+	if err != nil || u == nil {
+		return fmt.Errorf("user %q not found", *author)
+	}
+
+	importer := blog.NewImporter(
+		blog.NewStore(db),
+		fmt.Sprintf("%s/blogs/mds", config.Env.UploadsRootDir),
+		fmt.Sprintf("%s/blogs/images", config.Env.UploadsRootDir),
+		u.Id,
+		*cover,
+		*dryRun,
+	)
+
+	path := flags.Arg(0)
+	now := time.Now()
+
+	var report *types_blog.ImportReport
+	if strings.EqualFold(filepath.Ext(path), ".xml") {
+		var f *os.File
+		f, err = os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err = importer.ImportWXR(f, *uploads, now)
+	} else {
+		report, err = importer.ImportDirectory(path, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	for _, p := range report.Posts {
+		fmt.Printf("%-8s %s -> %s", p.Result, p.Source, p.Slug)
+		if p.Error != "" {
+			fmt.Printf(" (%s)", p.Error)
+		}
+		fmt.Println()
+
+		for _, warning := range p.Warnings {
+			fmt.Printf("         warning: %s\n", warning)
+		}
+	}
+
+	fmt.Printf(
+		"\n%s import: %d imported, %d skipped, %d failed",
+		report.Format,
+		report.Imported,
+		report.Skipped,
+		report.Failed,
+	)
+	if report.DryRun {
+		fmt.Print(" (dry run)")
+	}
+	fmt.Println()
+
+	return nil
+}
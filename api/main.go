@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/SaeedAlian/megavault/api/api"
 	"github.com/SaeedAlian/megavault/api/config"
@@ -18,6 +19,26 @@ func main() {
 
 	initStorage(db)
 
+	if len(os.Args) > 1 {
+		var err error
+
+		switch os.Args[1] {
+		case "fsck":
+			err = runFsck(db, os.Args[2:])
+		case "backfill-stats":
+			err = runBackfillStats(db, os.Args[2:])
+		case "import":
+			err = runImport(db, os.Args[2:])
+		default:
+			log.Fatalf("unknown command %q", os.Args[1])
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	server := api.NewServer(fmt.Sprintf(":%s", config.Env.Port), db)
 
 	if err := server.Run(); err != nil {
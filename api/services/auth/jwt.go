@@ -53,6 +53,65 @@ func WithJWTAuth(handler http.HandlerFunc, store types_user.UserStore) http.Hand
 	}
 }
 
+// WithOptionalJWTAuth lets anonymous requests through. A missing, expired or
+// otherwise invalid token is treated as no token at all, so a stale session
+// can still read the public pages.
+func WithOptionalJWTAuth(handler http.HandlerFunc, store types_user.UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			handler(w, r)
+			return
+		}
+
+		userId, err := authenticate(r, store)
+		if err != nil {
+			handler(w, r)
+			return
+		}
+
+		handler(w, r.WithContext(context.WithValue(r.Context(), "userId", userId)))
+	}
+}
+
+// IsAdmin reports whether the user authenticated on the request is an admin.
+func IsAdmin(r *http.Request, store types_user.UserStore) bool {
+	userId, ok := r.Context().Value("userId").(string)
+	if !ok {
+		return false
+	}
+
+	u, err := store.GetUserById(userId)
+	if err != nil || u == nil {
+		return false
+	}
+
+	return u.Role == types_user.UserRoleAdmin
+}
+
+// authenticate returns the id of the user the token of the request belongs to.
+func authenticate(r *http.Request, store types_user.UserStore) (string, error) {
+	claims := types_user.UserJWTClaims{}
+	token, err := ValidateJWT(r.Header.Get("Authorization"), &claims)
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid {
+		return "", fmt.Errorf("Invalid token")
+	}
+
+	u, err := store.GetUserById(claims.UserId)
+	if err != nil {
+		return "", err
+	}
+
+	if u == nil {
+		return "", fmt.Errorf("User of the token not found")
+	}
+
+	return u.Id, nil
+}
+
 func GenerateJWT(claims jwt.MapClaims, expiresAtInMinutes float64) (string, error) {
 	expiration := time.Minute * time.Duration(expiresAtInMinutes)
 
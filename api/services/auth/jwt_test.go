@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -57,3 +59,33 @@ func TestJWTValidation(t *testing.T) {
 		t.Error("There was an error on validating jwt: expiration time is not correct")
 	}
 }
+
+func TestOptionalJWTAuthWithInvalidToken(t *testing.T) {
+	handler := WithOptionalJWTAuth(func(w http.ResponseWriter, r *http.Request) {
+		if userId := r.Context().Value("userId"); userId != nil {
+			t.Errorf("Expected an anonymous request, received user %v", userId)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}, nil)
+
+	expired, err := GenerateJWT(jwt.MapClaims{"userId": "1"}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{"not-a-token", expired} {
+		req, err := http.NewRequest("GET", "/blog/blog1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", token)
+
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+	}
+}
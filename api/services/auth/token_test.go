@@ -0,0 +1,22 @@
+package auth
+
+import "testing"
+
+func TestGenerateToken(t *testing.T) {
+	token, hash, err := GenerateToken()
+	if err != nil {
+		t.Errorf("There was an error on generating the token: %v", err)
+	}
+
+	if token == "" || hash == "" {
+		t.Error("There was an error on generating the token: token or hash is empty")
+	}
+
+	if token == hash {
+		t.Error("There was an error on generating the token: hash is equal to the token")
+	}
+
+	if HashToken(token) != hash {
+		t.Error("There was an error on hashing the token: expected hash to match the token")
+	}
+}
@@ -0,0 +1,261 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/types/user"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+// blogRole returns the role the user has on the blog, or an empty string when
+// they aren't one of its authors.
+func blogRole(b *types_blog.Blog, userId string) string {
+	if userId == "" {
+		return ""
+	}
+
+	for _, a := range b.Authors {
+		if a.UserId == userId {
+			return a.Role
+		}
+	}
+
+	if b.AuthorId == userId {
+		return types_blog.BlogAuthorRoleOwner
+	}
+
+	return ""
+}
+
+func (h *Handler) canEdit(w http.ResponseWriter, r *http.Request, b *types_blog.Blog) bool {
+	return CanEdit(w, r, b, h.userStore)
+}
+
+// CanEdit reports whether the user on the request is the owner or a co-author
+// of the blog, or an admin, and writes a 403 response when they aren't.
+func CanEdit(
+	w http.ResponseWriter,
+	r *http.Request,
+	b *types_blog.Blog,
+	userStore types_user.UserStore,
+) bool {
+	userId, _ := r.Context().Value("userId").(string)
+
+	switch blogRole(b, userId) {
+	case types_blog.BlogAuthorRoleOwner, types_blog.BlogAuthorRoleCoAuthor:
+		return true
+	}
+
+	if auth.IsAdmin(r, userStore) {
+		return true
+	}
+
+	utils.WriteErrorInResponse(
+		w,
+		http.StatusForbidden,
+		"Only the owner and co-authors of the blog can edit it",
+	)
+	return false
+}
+
+func (h *Handler) canManage(
+	w http.ResponseWriter,
+	r *http.Request,
+	b *types_blog.Blog,
+	message string,
+) bool {
+	userId, _ := r.Context().Value("userId").(string)
+	if blogRole(b, userId) == types_blog.BlogAuthorRoleOwner || auth.IsAdmin(r, h.userStore) {
+		return true
+	}
+
+	utils.WriteErrorInResponse(w, http.StatusForbidden, message)
+	return false
+}
+
+func (h *Handler) inviteAuthor(w http.ResponseWriter, r *http.Request) {
+	b, err := h.store.GetBlogById(mux.Vars(r)["id"])
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canManage(w, r, b, "Only the owner of the blog can invite collaborators") {
+		return
+	}
+
+	var payload types_blog.InviteBlogAuthorPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid invitation payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	u, err := h.userStore.GetUserByUsername(payload.Username)
+	if err != nil || u == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if role := blogRole(b, u.Id); role == types_blog.BlogAuthorRoleOwner ||
+		role == payload.Role {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("The user is already the %s of this blog", role),
+		)
+		return
+	}
+
+	pending, err := h.store.GetPendingBlogInvitations(u.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	for _, i := range pending {
+		if i.BlogId == b.Id {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"The user has already been invited to this blog",
+			)
+			return
+		}
+	}
+
+	payload.BlogId = b.Id
+	payload.InviterId, _ = r.Context().Value("userId").(string)
+	payload.InviteeId = u.Id
+
+	invitation, err := h.store.CreateBlogInvitation(payload)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusCreated, invitation, nil)
+}
+
+func (h *Handler) getInvitations(w http.ResponseWriter, r *http.Request) {
+	userId, _ := r.Context().Value("userId").(string)
+
+	invitations, err := h.store.GetPendingBlogInvitations(userId)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_blog.BlogInvitation{
+		"result": invitations,
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) acceptInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvitation(w, r, true)
+}
+
+func (h *Handler) declineInvitation(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvitation(w, r, false)
+}
+
+func (h *Handler) respondToInvitation(w http.ResponseWriter, r *http.Request, accepted bool) {
+	userId, _ := r.Context().Value("userId").(string)
+
+	invitation, err := h.store.GetBlogInvitationById(mux.Vars(r)["id"])
+	if err != nil || invitation == nil || invitation.InviteeId != userId {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Invitation not found")
+		return
+	}
+
+	if invitation.Status != types_blog.BlogInvitationStatusPending {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The invitation has already been answered",
+		)
+		return
+	}
+
+	if err := h.store.RespondToBlogInvitation(invitation.Id, accepted); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	status := types_blog.BlogInvitationStatusDeclined
+	if accepted {
+		status = types_blog.BlogInvitationStatusAccepted
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Invitation with id %s has been %s", invitation.Id, status),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) removeAuthor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	b, err := h.store.GetBlogById(vars["id"])
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	authorId := vars["userId"]
+	role := blogRole(b, authorId)
+	if role == "" {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "The user is not an author of this blog")
+		return
+	}
+
+	if role == types_blog.BlogAuthorRoleOwner {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The owner of the blog can't be removed",
+		)
+		return
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	if userId != authorId &&
+		!h.canManage(w, r, b, "Only the owner of the blog can remove collaborators") {
+		return
+	}
+
+	if err := h.store.RemoveBlogAuthor(b.Id, authorId); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("User with id %s has been removed from the blog", authorId),
+		},
+		nil,
+	)
+}
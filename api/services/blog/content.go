@@ -0,0 +1,90 @@
+package blog
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+type renderedContent struct {
+	key  string
+	html string
+	toc  []utils.TocEntry
+}
+
+// contentCache keeps the most recently rendered blogs. Entries are keyed by
+// the markdown file and the blog's updatedAt, so editing a blog never serves
+// stale HTML and old renders simply age out.
+type contentCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newContentCache(size int) *contentCache {
+	return &contentCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (c *contentCache) render(
+	mdFilename string,
+	updatedAt time.Time,
+	content string,
+) (string, []utils.TocEntry) {
+	key := fmt.Sprintf("%s:%d", mdFilename, updatedAt.UnixNano())
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		rendered := e.Value.(*renderedContent)
+		c.mu.Unlock()
+
+		return rendered.html, rendered.toc
+	}
+	c.mu.Unlock()
+
+	html, toc := utils.RenderMarkdown(content)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = c.order.PushFront(&renderedContent{key: key, html: html, toc: toc})
+
+		for c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*renderedContent).key)
+		}
+	}
+
+	return html, toc
+}
+
+// renderContent returns the rendered HTML of the blog along with its markdown
+// body without the front matter.
+func (h *Handler) renderContent(
+	b *types_blog.Blog,
+) (string, []utils.TocEntry, string, bool) {
+	content, ok := h.blogContent(b)
+	if !ok {
+		return "", nil, "", false
+	}
+
+	_, body, err := utils.ParseFrontMatter(content)
+	if err != nil {
+		body = content
+	}
+
+	html, toc := h.contentCache.render(b.MDFilename, b.UpdatedAt, body)
+
+	return html, toc, body, true
+}
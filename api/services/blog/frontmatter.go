@@ -0,0 +1,149 @@
+package blog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	types_blog "github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+var frontMatterDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseFrontMatter reads the metadata of a markdown file. Hugo and Jekyll use
+// different names for the same fields, so the common aliases are accepted.
+func parseFrontMatter(content string) (*types_blog.FrontMatter, error) {
+	metadata, _, err := utils.ParseFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata == nil {
+		return nil, nil
+	}
+
+	text := func(keys ...string) string {
+		return frontMatterText(metadata, keys...)
+	}
+
+	fm := &types_blog.FrontMatter{
+		Title:       text("title"),
+		Description: text("description", "summary", "excerpt"),
+		CoverImage:  text("cover", "coverImage", "cover_image", "image"),
+		Slug:        text("slug"),
+	}
+
+	switch v := frontMatterValue(metadata, "tags").(type) {
+	case []string:
+		fm.Tags = v
+	case string:
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				fm.Tags = append(fm.Tags, t)
+			}
+		}
+	}
+
+	if date := text("publishDate", "date", "publishedAt"); date != "" {
+		var parsed *time.Time
+
+		for _, layout := range frontMatterDateLayouts {
+			if t, err := time.Parse(layout, date); err == nil {
+				parsed = &t
+				break
+			}
+		}
+
+		if parsed == nil {
+			return nil, fmt.Errorf("Invalid publish date '%s'", date)
+		}
+
+		fm.PublishDate = parsed
+	}
+
+	if fm.CoverImage != "" {
+		fm.CoverImage = filepath.Base(fm.CoverImage)
+	}
+
+	return fm, nil
+}
+
+func frontMatterValue(metadata map[string]any, keys ...string) any {
+	for _, k := range keys {
+		for mk, v := range metadata {
+			if strings.EqualFold(mk, k) {
+				return v
+			}
+		}
+	}
+
+	return nil
+}
+
+func frontMatterText(metadata map[string]any, keys ...string) string {
+	switch v := frontMatterValue(metadata, keys...).(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []string:
+		if len(v) > 0 {
+			return strings.TrimSpace(v[0])
+		}
+	}
+
+	return ""
+}
+
+// applyFrontMatter fills the fields the client left empty. Explicit payload
+// values always win over the file's metadata.
+func applyFrontMatter(
+	payload *types_blog.CreateBlogPayload,
+	fm *types_blog.FrontMatter,
+	now time.Time,
+) {
+	if fm == nil {
+		return
+	}
+
+	if payload.Title == "" {
+		payload.Title = fm.Title
+	}
+
+	if payload.Description == "" {
+		payload.Description = fm.Description
+	}
+
+	if payload.PictureName == "" {
+		payload.PictureName = fm.CoverImage
+	}
+
+	if payload.Slug == "" {
+		payload.Slug = fm.Slug
+	}
+
+	if payload.Tags == nil && fm.Tags != nil {
+		payload.Tags = fm.Tags
+	}
+
+	// The date never changes the status on its own: it dates a blog the client
+	// publishes, and times one it schedules without giving a time.
+	if fm.PublishDate == nil {
+		return
+	}
+
+	if fm.PublishDate.After(now) {
+		if payload.Status == types_blog.BlogStatusScheduled && payload.ScheduledAt == nil {
+			payload.ScheduledAt = fm.PublishDate
+		}
+	} else if payload.PublishedAt == nil {
+		payload.PublishedAt = fm.PublishDate
+	}
+}
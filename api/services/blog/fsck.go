@@ -0,0 +1,210 @@
+package blog
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+// contentImageRegex matches the links to uploaded images inside markdown, both
+// relative ones and the absolute ones written by the importer.
+var contentImageRegex = regexp.MustCompile(`/api/v1/blog/image/([^\])\s"'<>?#]+)`)
+
+// Vault checks that the upload directories and the blogs agree with each
+// other. Blogs pointing at files that don't exist are reported, and files no
+// blog or revision references are removed once they are older than the grace
+// period, so uploads that are still waiting for their createBlog call survive.
+type Vault struct {
+	store           types_blog.BlogStore
+	mdFileUploadDir string
+	imageUploadDir  string
+	grace           time.Duration
+	dryRun          bool
+}
+
+func NewVault(
+	store types_blog.BlogStore,
+	mdFileUploadDir string,
+	imageUploadDir string,
+	grace time.Duration,
+	dryRun bool,
+) *Vault {
+	return &Vault{
+		store:           store,
+		mdFileUploadDir: mdFileUploadDir,
+		imageUploadDir:  imageUploadDir,
+		grace:           grace,
+		dryRun:          dryRun,
+	}
+}
+
+func (v *Vault) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := v.Check(time.Now())
+			if err != nil {
+				log.Printf("failed to check the uploads: %v", err)
+				continue
+			}
+
+			if len(report.MissingFiles) > 0 || len(report.OrphanFiles) > 0 {
+				log.Printf(
+					"uploads check: %d missing files, %d orphan files, %d removed (%d bytes)",
+					len(report.MissingFiles),
+					len(report.OrphanFiles),
+					report.RemovedFiles,
+					report.ReclaimedSize,
+				)
+			}
+		}
+	}
+}
+
+func (v *Vault) Check(now time.Time) (*types_blog.FsckReport, error) {
+	blogs, err := v.store.GetBlogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := v.store.GetRevisionFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types_blog.FsckReport{
+		CheckedBlogs: len(blogs),
+		MissingFiles: []types_blog.MissingFile{},
+		OrphanFiles:  []types_blog.OrphanFile{},
+		DryRun:       v.dryRun,
+	}
+
+	mdFiles := map[string]bool{}
+	imageFiles := map[string]bool{}
+
+	for _, f := range append(blogs, revisions...) {
+		mdFiles[f.MDFilename] = true
+		imageFiles[f.PictureName] = true
+	}
+
+	// Images inside the content are only referenced by their links, so the
+	// content stored with the blogs and the uploaded markdown files are both
+	// searched for them.
+	names, err := v.store.GetContentImageNames()
+	if err != nil {
+		return nil, err
+	}
+
+	for md := range mdFiles {
+		content, err := os.ReadFile(filepath.Join(v.mdFileUploadDir, filepath.Base(md)))
+		if err != nil {
+			continue
+		}
+
+		for _, m := range contentImageRegex.FindAllStringSubmatch(string(content), -1) {
+			names = append(names, m[1])
+		}
+	}
+
+	for _, name := range names {
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			imageFiles[filepath.Base(unescaped)] = true
+		}
+	}
+
+	for _, b := range blogs {
+		if !v.exists(v.mdFileUploadDir, b.MDFilename) {
+			report.MissingFiles = append(report.MissingFiles, types_blog.MissingFile{
+				BlogId:   b.BlogId,
+				Slug:     b.Slug,
+				Kind:     "markdown",
+				Filename: b.MDFilename,
+			})
+		}
+
+		if !v.exists(v.imageUploadDir, b.PictureName) {
+			report.MissingFiles = append(report.MissingFiles, types_blog.MissingFile{
+				BlogId:   b.BlogId,
+				Slug:     b.Slug,
+				Kind:     "image",
+				Filename: b.PictureName,
+			})
+		}
+	}
+
+	if err := v.collect(report, v.mdFileUploadDir, mdFiles, now); err != nil {
+		return nil, err
+	}
+
+	if err := v.collect(report, v.imageUploadDir, imageFiles, now); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (v *Vault) exists(directory string, filename string) bool {
+	exists, err := utils.PathExists(filepath.Join(directory, filepath.Base(filename)))
+	return err == nil && exists
+}
+
+func (v *Vault) collect(
+	report *types_blog.FsckReport,
+	directory string,
+	referenced map[string]bool,
+	now time.Time,
+) error {
+	entries, err := os.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || referenced[e.Name()] {
+			continue
+		}
+
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+
+		orphan := types_blog.OrphanFile{
+			Path:       filepath.Join(directory, e.Name()),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+			Pending:    now.Sub(info.ModTime()) < v.grace,
+		}
+
+		if orphan.Pending {
+			report.PendingFiles++
+		} else if !v.dryRun {
+			if err := os.Remove(orphan.Path); err != nil {
+				log.Printf("failed to remove %s: %v", orphan.Path, err)
+			} else {
+				orphan.Removed = true
+				report.RemovedFiles++
+				report.ReclaimedSize += orphan.Size
+			}
+		}
+
+		report.OrphanFiles = append(report.OrphanFiles, orphan)
+	}
+
+	return nil
+}
@@ -0,0 +1,819 @@
+package blog
+
+import (
+	"archive/zip"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const (
+	importExcerptLength  = 160
+	maxImportSizeInMB    = 64
+	maxExtractedSizeInMB = 512
+	maxImportFiles       = 10000
+)
+
+var (
+	importImageRegex = regexp.MustCompile(
+		`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(\s+"[^"]*")?\s*\)`,
+	)
+	importFigureRegex    = regexp.MustCompile(`\{\{[<%]\s*figure\s+([^}]*?)\s*[>%]\}\}`)
+	importAttrRegex      = regexp.MustCompile(`(\w+)\s*=\s*"([^"]*)"`)
+	importShortcodeRegex = regexp.MustCompile(`\{\{[<%]\s*/?\s*([\w-]+)`)
+	importLiquidRegex    = regexp.MustCompile(`(?:^|[^{])\{%-?\s*(\w+)`)
+	importBaseURLRegex   = regexp.MustCompile(`\{\{\s*site\.baseurl\s*\}\}`)
+	jekyllFilenameRegex  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+)
+
+// importSource is a post read from an export, before its images are copied
+// and it is turned into a blog. resolve maps an image reference found in the
+// post to a file on disk. References to other sites resolve to an empty
+// string and are kept as they are, while local images that can't be found
+// return an error.
+type importSource struct {
+	source      string
+	title       string
+	description string
+	slug        string
+	tags        []string
+	date        *time.Time
+	draft       bool
+	cover       string
+	content     string
+	resolve     func(ref string) (string, error)
+}
+
+// Importer creates blogs from the posts of other blog engines. Images the
+// posts reference are copied into the upload directory and the references are
+// rewritten to point at them. Slugs and publish dates are kept, so posts whose
+// slug is already taken are skipped instead of being renamed, which also
+// makes running the same import twice harmless.
+type Importer struct {
+	store           types_blog.BlogStore
+	mdFileUploadDir string
+	imageUploadDir  string
+	authorId        string
+	defaultCover    string
+	dryRun          bool
+	copied          map[string]string
+	slugs           map[string]bool
+}
+
+func NewImporter(
+	store types_blog.BlogStore,
+	mdFileUploadDir string,
+	imageUploadDir string,
+	authorId string,
+	defaultCover string,
+	dryRun bool,
+) *Importer {
+	return &Importer{
+		store:           store,
+		mdFileUploadDir: mdFileUploadDir,
+		imageUploadDir:  imageUploadDir,
+		authorId:        authorId,
+		defaultCover:    defaultCover,
+		dryRun:          dryRun,
+		copied:          map[string]string{},
+		slugs:           map[string]bool{},
+	}
+}
+
+// ImportDirectory imports a Jekyll site, a Hugo site or a plain directory of
+// markdown files. Jekyll sites are recognised by their _posts directory and
+// Hugo sites by their content directory.
+func (i *Importer) ImportDirectory(root string, now time.Time) (*types_blog.ImportReport, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(root); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	report := &types_blog.ImportReport{
+		Format: types_blog.ImportFormatMarkdown,
+		DryRun: i.dryRun,
+		Posts:  []types_blog.ImportPost{},
+	}
+
+	dirs := []string{root}
+
+	if isDir(filepath.Join(root, "_posts")) {
+		report.Format = types_blog.ImportFormatJekyll
+		dirs = []string{filepath.Join(root, "_posts"), filepath.Join(root, "_drafts")}
+	} else if isDir(filepath.Join(root, "content")) {
+		report.Format = types_blog.ImportFormatHugo
+		dirs = []string{filepath.Join(root, "content")}
+	}
+
+	for _, dir := range dirs {
+		if !isDir(dir) {
+			continue
+		}
+
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if path != dir && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			ext := strings.ToLower(filepath.Ext(path))
+			if (ext != ".md" && ext != ".markdown") || d.Name() == "_index.md" {
+				return nil
+			}
+
+			source, _ := filepath.Rel(root, path)
+
+			post, err := i.readMarkdownPost(root, path, report.Format)
+			if err != nil {
+				addImportPost(report, types_blog.ImportPost{
+					Source: source,
+					Result: types_blog.ImportResultFailed,
+					Error:  err.Error(),
+				})
+				return nil
+			}
+
+			post.source = source
+			addImportPost(report, i.importPost(post, now))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (i *Importer) readMarkdownPost(
+	root string,
+	path string,
+	format string,
+) (importSource, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return importSource{}, err
+	}
+
+	metadata, body, err := utils.ParseFrontMatter(string(raw))
+	if err != nil {
+		return importSource{}, fmt.Errorf("Invalid front matter: %v", err)
+	}
+
+	fm, err := parseFrontMatter(string(raw))
+	if err != nil {
+		return importSource{}, fmt.Errorf("Invalid front matter: %v", err)
+	}
+	if fm == nil {
+		fm = &types_blog.FrontMatter{}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if name == "index" {
+		name = filepath.Base(filepath.Dir(path))
+	}
+
+	post := importSource{
+		title:       fm.Title,
+		description: fm.Description,
+		slug:        fm.Slug,
+		tags:        fm.Tags,
+		date:        fm.PublishDate,
+		cover:       frontMatterText(metadata, "cover", "coverImage", "cover_image", "image"),
+		content:     importBaseURLRegex.ReplaceAllString(body, ""),
+		draft: strings.EqualFold(frontMatterText(metadata, "draft"), "true") ||
+			strings.EqualFold(frontMatterText(metadata, "published"), "false"),
+	}
+
+	if format == types_blog.ImportFormatJekyll {
+		if filepath.Base(filepath.Dir(path)) == "_drafts" {
+			post.draft = true
+		}
+
+		if m := jekyllFilenameRegex.FindStringSubmatch(name); m != nil {
+			name = m[2]
+			if date, err := time.Parse("2006-01-02", m[1]); err == nil && post.date == nil {
+				post.date = &date
+			}
+		}
+	}
+
+	if post.slug == "" {
+		post.slug = name
+	}
+
+	if post.title == "" {
+		post.title = name
+	}
+
+	dir := filepath.Dir(path)
+	post.cover = importBaseURLRegex.ReplaceAllString(post.cover, "")
+	post.resolve = func(ref string) (string, error) {
+		u, err := url.Parse(ref)
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+			return "", nil
+		}
+
+		candidates := []string{filepath.Join(dir, filepath.FromSlash(u.Path))}
+		if strings.HasPrefix(u.Path, "/") {
+			candidates = []string{
+				filepath.Join(root, "static", filepath.FromSlash(u.Path)),
+				filepath.Join(root, filepath.FromSlash(u.Path)),
+			}
+		}
+
+		for _, c := range candidates {
+			if rel, err := filepath.Rel(root, c); err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+
+			if info, err := os.Stat(c); err == nil && !info.IsDir() {
+				return c, nil
+			}
+		}
+
+		return "", fmt.Errorf("Image %s was not found", ref)
+	}
+
+	return post, nil
+}
+
+func (i *Importer) importPost(p importSource, now time.Time) types_blog.ImportPost {
+	post := types_blog.ImportPost{
+		Source:   p.source,
+		Title:    strings.TrimSpace(p.title),
+		Tags:     p.tags,
+		Images:   []string{},
+		Warnings: []string{},
+	}
+
+	fail := func(err string) types_blog.ImportPost {
+		post.Result = types_blog.ImportResultFailed
+		post.Error = err
+		return post
+	}
+
+	if post.Title == "" {
+		return fail("The post has no title")
+	}
+
+	slug := utils.CreateSlug(p.slug)
+	if slug == "" || reservedSlugs[slug] {
+		slug = utils.CreateSlug(post.Title)
+		post.Warnings = append(
+			post.Warnings,
+			fmt.Sprintf("The slug '%s' can't be used, '%s' is used instead", p.slug, slug),
+		)
+	}
+	post.Slug = slug
+
+	switch {
+	case slug == "":
+		return fail(errInvalidSlug.Error())
+	case reservedSlugs[slug]:
+		return fail(errReservedSlug.Error())
+	}
+
+	if i.slugTaken(slug) {
+		post.Result = types_blog.ImportResultSkipped
+		post.Error = errTakenSlug.Error()
+		return post
+	}
+
+	if len(post.Tags) > 20 {
+		post.Warnings = append(post.Warnings, "Only the first 20 tags are kept")
+		post.Tags = post.Tags[:20]
+	}
+
+	for n, t := range post.Tags {
+		if len(t) > 63 {
+			post.Tags[n] = t[:63]
+		}
+	}
+
+	copied := []string{}
+	mdPath := ""
+	created := false
+	defer func() {
+		if created || i.dryRun {
+			return
+		}
+
+		for _, path := range copied {
+			os.Remove(filepath.Join(i.imageUploadDir, i.copied[path]))
+			delete(i.copied, path)
+		}
+
+		if mdPath != "" {
+			os.Remove(mdPath)
+		}
+	}()
+
+	image := func(ref string) (string, bool) {
+		path, err := p.resolve(ref)
+		if err != nil {
+			post.Warnings = append(post.Warnings, err.Error())
+			return "", false
+		}
+
+		if path == "" {
+			return "", false
+		}
+
+		name, isNew, err := i.copyImage(path)
+		if err != nil {
+			post.Warnings = append(post.Warnings, fmt.Sprintf("Skipped image %s: %v", ref, err))
+			return "", false
+		}
+
+		if isNew {
+			copied = append(copied, path)
+		}
+
+		if !slices.Contains(post.Images, name) {
+			post.Images = append(post.Images, name)
+		}
+
+		return name, true
+	}
+
+	content := importFigureRegex.ReplaceAllStringFunc(p.content, func(m string) string {
+		attrs := map[string]string{}
+		for _, a := range importAttrRegex.FindAllStringSubmatch(m, -1) {
+			attrs[strings.ToLower(a[1])] = a[2]
+		}
+
+		alt := attrs["alt"]
+		if alt == "" {
+			alt = attrs["caption"]
+		}
+
+		return fmt.Sprintf("![%s](%s)", alt, utils.MarkdownURL(attrs["src"]))
+	})
+
+	content = importImageRegex.ReplaceAllStringFunc(content, func(m string) string {
+		parts := importImageRegex.FindStringSubmatch(m)
+
+		name, ok := image(parts[2])
+		if !ok {
+			return m
+		}
+
+		return fmt.Sprintf("![%s](%s%s)", parts[1], utils.MarkdownURL(blogImageURL(name)), parts[3])
+	})
+
+	for _, m := range importShortcodeRegex.FindAllStringSubmatch(content, -1) {
+		post.Warnings = append(
+			post.Warnings,
+			fmt.Sprintf("The '%s' shortcode is not supported and was kept as text", m[1]),
+		)
+	}
+
+	for _, m := range importLiquidRegex.FindAllStringSubmatch(content, -1) {
+		post.Warnings = append(
+			post.Warnings,
+			fmt.Sprintf("The '%s' liquid tag is not supported and was kept as text", m[1]),
+		)
+	}
+
+	cover := ""
+	if p.cover != "" {
+		cover, _ = image(p.cover)
+	}
+
+	if cover == "" && len(post.Images) > 0 {
+		cover = post.Images[0]
+	}
+
+	if cover == "" && i.defaultCover != "" {
+		name, isNew, err := i.copyImage(i.defaultCover)
+		if err != nil {
+			return fail(fmt.Sprintf("The default cover can't be used: %v", err))
+		}
+
+		if isNew {
+			copied = append(copied, i.defaultCover)
+		}
+
+		cover = name
+	}
+
+	if cover == "" {
+		return fail("The post has no image to use as its cover")
+	}
+
+	description := strings.TrimSpace(p.description)
+	if description == "" {
+		description = excerpt(content, importExcerptLength)
+	}
+	if description == "" {
+		description = post.Title
+	}
+
+	payload := types_blog.CreateBlogPayload{
+		Title:       post.Title,
+		Description: description,
+		Slug:        slug,
+		PictureName: cover,
+		Visibility:  types_blog.BlogVisibilityPublic,
+		Status:      types_blog.BlogStatusDraft,
+		Tags:        post.Tags,
+		AuthorId:    i.authorId,
+		Content:     content,
+		Stats:       readingStats(content),
+	}
+
+	switch {
+	case p.draft:
+	case p.date != nil && p.date.After(now):
+		payload.Status = types_blog.BlogStatusScheduled
+		payload.ScheduledAt = p.date
+	case p.date != nil:
+		payload.Status = types_blog.BlogStatusPublished
+		payload.PublishedAt = p.date
+	default:
+		payload.Status = types_blog.BlogStatusPublished
+		payload.PublishedAt = &now
+	}
+
+	if p.date != nil && !p.date.After(now) {
+		payload.CreatedAt = p.date
+	}
+
+	post.Status = payload.Status
+	post.PublishedAt = payload.PublishedAt
+	if payload.ScheduledAt != nil {
+		post.PublishedAt = payload.ScheduledAt
+	}
+
+	i.slugs[slug] = true
+
+	if i.dryRun {
+		post.Result = types_blog.ImportResultImported
+		return post
+	}
+
+	if err := os.MkdirAll(i.mdFileUploadDir, os.ModePerm); err != nil {
+		return fail(err.Error())
+	}
+
+	payload.MDFilename = fmt.Sprintf("%d-%s.md", time.Now().UnixNano(), slug)
+	mdPath = filepath.Join(i.mdFileUploadDir, payload.MDFilename)
+	if err := os.WriteFile(mdPath, []byte(content), 0644); err != nil {
+		return fail(err.Error())
+	}
+
+	b, err := i.store.CreateBlog(payload)
+	if err != nil {
+		return fail(err.Error())
+	}
+
+	created = true
+	post.BlogId = b.Id
+	post.Result = types_blog.ImportResultImported
+
+	return post
+}
+
+// copyImage copies an image into the upload directory once, however many
+// posts reference it, and returns its uploaded name.
+func (i *Importer) copyImage(path string) (string, bool, error) {
+	if name, ok := i.copied[path]; ok {
+		return name, false, nil
+	}
+
+	mime, err := mimetype.DetectFile(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	allowed := false
+	for _, m := range imageMimeTypes {
+		if mime.Is(m) {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return "", false, fmt.Errorf("%s images are not supported", mime.String())
+	}
+
+	name := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(path))
+
+	if !i.dryRun {
+		if err := copyFile(path, filepath.Join(i.imageUploadDir, name)); err != nil {
+			return "", false, err
+		}
+	}
+
+	i.copied[path] = name
+
+	return name, true, nil
+}
+
+// slugTaken also counts the old slugs of other blogs, so importing a post
+// doesn't break the redirects of a renamed blog.
+func (i *Importer) slugTaken(slug string) bool {
+	if i.slugs[slug] {
+		return true
+	}
+
+	if b, _ := i.store.GetBlogBySlug(slug); b != nil {
+		return true
+	}
+
+	b, _ := i.store.GetBlogByOldSlug(slug)
+	return b != nil
+}
+
+func addImportPost(report *types_blog.ImportReport, post types_blog.ImportPost) {
+	switch post.Result {
+	case types_blog.ImportResultImported:
+		report.Imported++
+	case types_blog.ImportResultSkipped:
+		report.Skipped++
+	default:
+		report.Failed++
+	}
+
+	report.Posts = append(report.Posts, post)
+}
+
+func copyFile(src string, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+
+	return out.Close()
+}
+
+// excerpt returns the first words of the rendered post, cut at a word
+// boundary.
+func excerpt(content string, length int) string {
+	rendered, _ := utils.RenderMarkdown(content)
+	rendered = statsCodeBlockRegex.ReplaceAllString(rendered, " ")
+
+	words := strings.Fields(html.UnescapeString(statsTagRegex.ReplaceAllString(rendered, " ")))
+
+	text := ""
+	for _, w := range words {
+		if len(text)+len(w)+1 > length {
+			return text + "…"
+		}
+
+		text = strings.TrimSpace(text + " " + w)
+	}
+
+	return text
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func (h *Handler) importBlogs(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can import blogs")
+		return
+	}
+
+	if !utils.ParseMultipartRequest(w, r, maxImportSizeInMB) {
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Cannot retrieve the file: %v", err),
+		)
+		return
+	}
+	defer file.Close()
+
+	dryRun, _ := strconv.ParseBool(r.FormValue("dryRun"))
+
+	dir, err := os.MkdirTemp("", "megavault-import-")
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cover := ""
+	if _, ok := r.MultipartForm.File["cover"]; ok {
+		name, ok := utils.SaveFormFile(w, r, "cover", maxImageSizeInMB, imageMimeTypes, dir)
+		if !ok {
+			return
+		}
+
+		cover = filepath.Join(dir, name)
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	importer := NewImporter(h.store, h.mdFileUploadDir, h.imageUploadDir, userId, cover, dryRun)
+
+	var report *types_blog.ImportReport
+	now := time.Now()
+
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".xml":
+		report, err = importer.ImportWXR(file, "", now)
+	case ".zip":
+		root := filepath.Join(dir, "export")
+		if err := extractZip(file, header.Size, root); err != nil {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				fmt.Sprintf("Invalid archive: %v", err),
+			)
+			return
+		}
+
+		report, err = importer.importArchive(root, now)
+	default:
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Please upload a WordPress export (.xml) or a zip archive of the site",
+		)
+		return
+	}
+
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !dryRun && report.Imported > 0 {
+		h.related.invalidate()
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, report, nil)
+}
+
+// importArchive imports an extracted archive. Archives holding a WordPress
+// export are read with the uploads next to it, anything else is imported as
+// a directory of markdown.
+func (i *Importer) importArchive(root string, now time.Time) (*types_blog.ImportReport, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(root, entries[0].Name())
+	}
+
+	export := ""
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".xml") {
+			export = path
+			return filepath.SkipAll
+		}
+
+		return nil
+	})
+
+	if export == "" {
+		return i.ImportDirectory(root, now)
+	}
+
+	f, err := os.Open(export)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	uploadsDir := root
+	for _, dir := range []string{"wp-content/uploads", "uploads"} {
+		if isDir(filepath.Join(root, dir)) {
+			uploadsDir = filepath.Join(root, dir)
+			break
+		}
+	}
+
+	return i.ImportWXR(f, uploadsDir, now)
+}
+
+// extractZip refuses entries that would land outside dst and stops once the
+// extracted files grow past the import size limit, so a small archive can't
+// fill the disk.
+func extractZip(r io.ReaderAt, size int64, dst string) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	if len(archive.File) > maxImportFiles {
+		return fmt.Errorf("The archive has more than %d files", maxImportFiles)
+	}
+
+	remaining := int64(maxExtractedSizeInMB) * 1024 * 1024
+
+	for _, f := range archive.File {
+		path := filepath.Join(dst, filepath.FromSlash(f.Name))
+		if rel, err := filepath.Rel(dst, path); err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("The archive contains an invalid path %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return err
+		}
+
+		written, err := extractZipFile(f, path, remaining)
+		if err != nil {
+			return err
+		}
+
+		remaining -= written
+	}
+
+	return nil
+}
+
+func extractZipFile(f *zip.File, path string, limit int64) (int64, error) {
+	in, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, io.LimitReader(in, limit+1))
+	if err != nil {
+		return written, err
+	}
+
+	if written > limit {
+		return written, fmt.Errorf(
+			"The extracted archive is bigger than %dMB",
+			maxExtractedSizeInMB,
+		)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,121 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 20
+)
+
+type relatedEntry struct {
+	blogs     []types_blog.Blog
+	expiresAt time.Time
+}
+
+// relatedCache keeps the scored related blogs per source blog. Any change to a
+// blog can move it in or out of other blogs' results, so every write bumps the
+// generation instead of tracking which entries are affected. Entries also
+// expire, which covers changes made to the database by other processes.
+type relatedCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	generation int64
+	entries    map[string]relatedEntry
+}
+
+func newRelatedCache(ttl time.Duration) *relatedCache {
+	return &relatedCache{
+		ttl:     ttl,
+		entries: map[string]relatedEntry{},
+	}
+}
+
+func (c *relatedCache) get(key string, now time.Time) ([]types_blog.Blog, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[fmt.Sprintf("%d:%s", c.generation, key)]
+	if !ok || now.After(e.expiresAt) {
+		return nil, false
+	}
+
+	return e.blogs, true
+}
+
+func (c *relatedCache) set(key string, blogs []types_blog.Blog, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[fmt.Sprintf("%d:%s", c.generation, key)] = relatedEntry{
+		blogs:     blogs,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+func (c *relatedCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = map[string]relatedEntry{}
+}
+
+func (h *Handler) getRelated(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	limit := defaultRelatedLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > maxRelatedLimit {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				fmt.Sprintf("The limit must be between 1 and %d", maxRelatedLimit),
+			)
+			return
+		}
+
+		limit = parsed
+	}
+
+	b, err := h.store.GetBlogBySlug(slug)
+	if err != nil || b == nil {
+		if h.redirectOldSlug(w, r, slug) {
+			return
+		}
+
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canRead(w, r, b) {
+		return
+	}
+
+	now := time.Now()
+	key := fmt.Sprintf("%s:%d", b.Id, limit)
+
+	related, ok := h.related.get(key, now)
+	if !ok {
+		related, err = h.store.GetRelatedBlogs(b.Id, limit)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+			return
+		}
+
+		h.related.set(key, related, now)
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, related, nil)
+}
@@ -1,8 +1,13 @@
 package blog
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,11 +20,26 @@ import (
 	"github.com/SaeedAlian/megavault/api/utils"
 )
 
+const (
+	maxMdFileSizeInMB = 5
+	maxImageSizeInMB  = 3
+	// maxBlogFormSizeInMB bounds a create request carrying both files.
+	maxBlogFormSizeInMB = 8
+)
+
+var (
+	mdMimeTypes    = []string{"text/markdown"}
+	imageMimeTypes = []string{"image/jpeg", "image/png", "image/jpg", "image/webp"}
+)
+
 type Handler struct {
 	store           types_blog.BlogStore
 	userStore       types_user.UserStore
 	mdFileUploadDir string
 	imageUploadDir  string
+	views           *ViewCounter
+	contentCache    *contentCache
+	related         *relatedCache
 }
 
 func NewHandler(
@@ -27,21 +47,92 @@ func NewHandler(
 	userStore types_user.UserStore,
 	mdFileUploadDir string,
 	imageUploadDir string,
+	views *ViewCounter,
 ) *Handler {
 	return &Handler{
 		store:           store,
 		userStore:       userStore,
 		mdFileUploadDir: mdFileUploadDir,
 		imageUploadDir:  imageUploadDir,
+		views:           views,
+		contentCache:    newContentCache(256),
+		related:         newRelatedCache(10 * time.Minute),
 	}
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/", auth.WithJWTAuth(h.getBlogs, h.userStore)).Methods("GET")
-	router.HandleFunc("/{slug}", auth.WithJWTAuth(h.getBlog, h.userStore)).Methods("GET")
+	router.HandleFunc("/", auth.WithOptionalJWTAuth(h.getBlogs, h.userStore)).Methods("GET")
+	router.HandleFunc("/feed", auth.WithJWTAuth(h.getFeed, h.userStore)).Methods("GET")
+	router.HandleFunc("/tags", h.getTags).Methods("GET")
+	router.HandleFunc("/tags/{tag}", auth.WithJWTAuth(h.renameTag, h.userStore)).Methods("PATCH")
+	router.HandleFunc("/tags/{tag}/merge", auth.WithJWTAuth(h.mergeTags, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/categories", h.getCategories).Methods("GET")
+	router.HandleFunc("/categories", auth.WithJWTAuth(h.createCategory, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/feed.{format:rss|atom|json}", h.getSyndicationFeed).Methods("GET")
+	router.HandleFunc("/invitations", auth.WithJWTAuth(h.getInvitations, h.userStore)).
+		Methods("GET")
+	router.HandleFunc(
+		"/invitations/{id}/accept",
+		auth.WithJWTAuth(h.acceptInvitation, h.userStore),
+	).Methods("POST")
+	router.HandleFunc(
+		"/invitations/{id}/decline",
+		auth.WithJWTAuth(h.declineInvitation, h.userStore),
+	).Methods("POST")
+	router.HandleFunc("/series", h.getSeriesList).Methods("GET")
+	router.HandleFunc("/series", auth.WithJWTAuth(h.createSeries, h.userStore)).Methods("POST")
+	router.HandleFunc("/series/{slug}", auth.WithOptionalJWTAuth(h.getSeries, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/series/{id}", auth.WithJWTAuth(h.updateSeries, h.userStore)).
+		Methods("PATCH")
+	router.HandleFunc("/series/{id}", auth.WithJWTAuth(h.deleteSeries, h.userStore)).
+		Methods("DELETE")
+	router.HandleFunc("/series/{id}/parts", auth.WithJWTAuth(h.addSeriesPart, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/series/{id}/parts", auth.WithJWTAuth(h.reorderSeries, h.userStore)).
+		Methods("PUT")
+	router.HandleFunc(
+		"/series/{id}/parts/{blogId}",
+		auth.WithJWTAuth(h.moveSeriesPart, h.userStore),
+	).Methods("PATCH")
+	router.HandleFunc(
+		"/series/{id}/parts/{blogId}",
+		auth.WithJWTAuth(h.removeSeriesPart, h.userStore),
+	).Methods("DELETE")
+	router.HandleFunc("/import", auth.WithJWTAuth(h.importBlogs, h.userStore)).Methods("POST")
+	router.HandleFunc("/image/{name}", h.getImage).Methods("GET")
+	router.HandleFunc("/{slug}", auth.WithOptionalJWTAuth(h.getBlog, h.userStore)).Methods("GET")
+	router.HandleFunc("/{slug}/content", auth.WithOptionalJWTAuth(h.getContent, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/{slug}/meta", auth.WithOptionalJWTAuth(h.getMeta, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/{slug}/related", auth.WithOptionalJWTAuth(h.getRelated, h.userStore)).
+		Methods("GET")
 	router.HandleFunc("/", auth.WithJWTAuth(h.createBlog, h.userStore)).Methods("POST")
 	router.HandleFunc("/md", auth.WithJWTAuth(h.uploadMdFile(), h.userStore)).Methods("POST")
 	router.HandleFunc("/image", auth.WithJWTAuth(h.uploadImage(), h.userStore)).Methods("POST")
+	router.HandleFunc("/{id}/publish", auth.WithJWTAuth(h.publishBlog, h.userStore)).Methods("POST")
+	router.HandleFunc("/{id}/unpublish", auth.WithJWTAuth(h.unpublishBlog, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/{id}/archive", auth.WithJWTAuth(h.archiveBlog, h.userStore)).Methods("POST")
+	router.HandleFunc("/{id}/invitations", auth.WithJWTAuth(h.inviteAuthor, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/{id}/authors/{userId}", auth.WithJWTAuth(h.removeAuthor, h.userStore)).
+		Methods("DELETE")
+	router.HandleFunc("/{id}/reactions", auth.WithJWTAuth(h.toggleReaction, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/{id}/revisions", auth.WithJWTAuth(h.getRevisions, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/{id}/revisions/diff", auth.WithJWTAuth(h.diffRevisions, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/{id}/revisions/{rev}", auth.WithJWTAuth(h.getRevision, h.userStore)).
+		Methods("GET")
+	router.HandleFunc(
+		"/{id}/revisions/{rev}/restore",
+		auth.WithJWTAuth(h.restoreRevision, h.userStore),
+	).Methods("POST")
 	router.HandleFunc("/{id}", auth.WithJWTAuth(h.updateBlog, h.userStore)).Methods("PATCH")
 	router.HandleFunc("/{id}", auth.WithJWTAuth(h.deleteBlog, h.userStore)).Methods("DELETE")
 }
@@ -49,8 +140,8 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 func (h *Handler) uploadImage() http.HandlerFunc {
 	blogImageUploadHandler := utils.FileUploadHandler(
 		"image",
-		3,
-		[]string{"image/jpeg", "image/png", "image/jpg", "image/webp"},
+		maxImageSizeInMB,
+		imageMimeTypes,
 		h.imageUploadDir,
 	)
 
@@ -58,23 +149,86 @@ func (h *Handler) uploadImage() http.HandlerFunc {
 }
 
 func (h *Handler) uploadMdFile() http.HandlerFunc {
-	blogMdUploadHandler := utils.FileUploadHandler(
-		"mdFile",
-		5,
-		[]string{"text/markdown"},
-		h.mdFileUploadDir,
-	)
+	return func(w http.ResponseWriter, r *http.Request) {
+		filename, ok := utils.SaveUploadedFile(
+			w,
+			r,
+			"mdFile",
+			maxMdFileSizeInMB,
+			mdMimeTypes,
+			h.mdFileUploadDir,
+		)
+		if !ok {
+			return
+		}
+
+		content := h.readMdFile(filename)
+
+		fm, err := parseFrontMatter(content)
+		if err != nil {
+			os.Remove(filepath.Join(h.mdFileUploadDir, filename))
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				fmt.Sprintf("Invalid front matter: %v", err),
+			)
+			return
+		}
 
-	return blogMdUploadHandler
+		utils.WriteJSONInResponse(w, http.StatusOK, types_blog.MdUploadResult{
+			Message: fmt.Sprintf(
+				"File with the name '%s' has been uploaded successfully",
+				filename,
+			),
+			Filename:    filename,
+			FrontMatter: fm,
+			Stats:       readingStats(content),
+		}, nil)
+	}
 }
 
 func (h *Handler) createBlog(w http.ResponseWriter, r *http.Request) {
 	var payload types_blog.CreateBlogPayload
-	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+
+	var uploaded []string
+	created := false
+	defer func() {
+		if created {
+			return
+		}
+
+		for _, path := range uploaded {
+			os.Remove(path)
+		}
+	}()
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		var ok bool
+		uploaded, ok = h.parseMultipartBlog(w, r, &payload)
+		if !ok {
+			return
+		}
+	} else if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
 		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid blog payload")
 		return
 	}
 
+	now := time.Now()
+
+	if payload.MDFilename != "" {
+		fm, err := parseFrontMatter(h.readMdFile(payload.MDFilename))
+		if err != nil {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				fmt.Sprintf("Invalid front matter: %v", err),
+			)
+			return
+		}
+
+		applyFrontMatter(&payload, fm, now)
+	}
+
 	if err := utils.Validator.Struct(payload); err != nil {
 		errors := err.(validator.ValidationErrors)
 		utils.WriteErrorInResponse(
@@ -85,15 +239,14 @@ func (h *Handler) createBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slug := utils.CreateSlug(payload.Title)
-
-	if b, _ := h.store.GetBlogBySlug(slug); b != nil {
-		utils.WriteErrorInResponse(
-			w,
-			http.StatusBadRequest,
-			"Another blog with that title already exists",
-		)
-		return
+	slug := h.uniqueSlug(payload.Title, "")
+	if payload.Slug != "" {
+		var err error
+		slug, err = h.customSlug(payload.Slug, "")
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
 	isMdExists, err := utils.PathExists(fmt.Sprintf("%s/%s", h.mdFileUploadDir, payload.MDFilename))
@@ -136,12 +289,73 @@ func (h *Handler) createBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if payload.CategoryId != "" {
+		if c, _ := h.store.GetCategoryById(payload.CategoryId); c == nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Category doesn't exist")
+			return
+		}
+	}
+
+	authorId, _ := r.Context().Value("userId").(string)
+
+	visibility := payload.Visibility
+	if visibility == "" {
+		visibility = types_blog.BlogVisibilityPublic
+	}
+
+	status := types_blog.UpdateBlogStatusPayload{
+		Status:    types_blog.BlogStatusDraft,
+		ExpiresAt: payload.ExpiresAt,
+	}
+
+	switch payload.Status {
+	case types_blog.BlogStatusPublished:
+		status.Status = types_blog.BlogStatusPublished
+		status.PublishedAt = &now
+		if payload.PublishedAt != nil {
+			status.PublishedAt = payload.PublishedAt
+		}
+	case types_blog.BlogStatusScheduled:
+		if !payload.ScheduledAt.After(now) {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"The scheduled publish time must be in the future",
+			)
+			return
+		}
+
+		status.Status = types_blog.BlogStatusScheduled
+		status.ScheduledAt = payload.ScheduledAt
+	}
+
+	if !isValidExpiry(status, now) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The expiry time must be after the publish time",
+		)
+		return
+	}
+
+	content := h.readMdFile(payload.MDFilename)
+
 	b, err := h.store.CreateBlog(types_blog.CreateBlogPayload{
 		Title:       payload.Title,
 		Description: payload.Description,
 		PictureName: payload.PictureName,
 		MDFilename:  payload.MDFilename,
 		Slug:        slug,
+		Visibility:  visibility,
+		Status:      status.Status,
+		PublishedAt: status.PublishedAt,
+		ScheduledAt: status.ScheduledAt,
+		ExpiresAt:   status.ExpiresAt,
+		CategoryId:  payload.CategoryId,
+		Tags:        payload.Tags,
+		AuthorId:    authorId,
+		Content:     content,
+		Stats:       readingStats(content),
 	})
 	if err != nil {
 		utils.WriteErrorInResponse(
@@ -152,17 +366,164 @@ func (h *Handler) createBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.related.invalidate()
+
+	created = true
+
 	utils.WriteJSONInResponse(w, http.StatusCreated, b, nil)
 }
 
+// parseMultipartBlog reads a create request that carries the metadata as a
+// JSON "metadata" field next to the "mdFile" and "image" files, so a blog can
+// be created in a single request. The saved files are returned so they can be
+// removed if the blog is not created.
+func (h *Handler) parseMultipartBlog(
+	w http.ResponseWriter,
+	r *http.Request,
+	payload *types_blog.CreateBlogPayload,
+) ([]string, bool) {
+	if !utils.ParseMultipartRequest(w, r, maxBlogFormSizeInMB) {
+		return nil, false
+	}
+
+	if metadata := r.FormValue("metadata"); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), payload); err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid blog payload")
+			return nil, false
+		}
+	}
+
+	uploaded := []string{}
+
+	if _, ok := r.MultipartForm.File["mdFile"]; ok {
+		filename, ok := utils.SaveFormFile(
+			w,
+			r,
+			"mdFile",
+			maxMdFileSizeInMB,
+			mdMimeTypes,
+			h.mdFileUploadDir,
+		)
+		if !ok {
+			return uploaded, false
+		}
+
+		payload.MDFilename = filename
+		uploaded = append(uploaded, filepath.Join(h.mdFileUploadDir, filename))
+	}
+
+	if _, ok := r.MultipartForm.File["image"]; ok {
+		filename, ok := utils.SaveFormFile(
+			w,
+			r,
+			"image",
+			maxImageSizeInMB,
+			imageMimeTypes,
+			h.imageUploadDir,
+		)
+		if !ok {
+			return uploaded, false
+		}
+
+		payload.PictureName = filename
+		uploaded = append(uploaded, filepath.Join(h.imageUploadDir, filename))
+	}
+
+	return uploaded, true
+}
+
 func (h *Handler) getBlogs(w http.ResponseWriter, r *http.Request) {
-	keywordQuery := r.URL.Query().Get("keyword")
+	params := r.URL.Query()
 
 	query := types_blog.SearchBlogQuery{
-		Keyword: strings.ToLower(keywordQuery),
+		Keyword:    strings.ToLower(params.Get("keyword")),
+		SortBy:     params.Get("sortBy"),
+		SortOrder:  strings.ToLower(params.Get("sortOrder")),
+		Cursor:     params.Get("cursor"),
+		Status:     params.Get("status"),
+		Tag:        utils.CreateSlug(params.Get("tag")),
+		Category:   params.Get("category"),
+		PublicOnly: r.Context().Value("userId") == nil,
+		Page:       1,
+		Limit:      20,
+	}
+
+	if query.SortBy == "" && query.Keyword != "" {
+		query.SortBy = "relevance"
+	}
+
+	if query.Status == "" {
+		query.Status = types_blog.BlogStatusPublished
+	}
+
+	if query.Status != types_blog.BlogStatusPublished {
+		userId, ok := r.Context().Value("userId").(string)
+		if !ok {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusUnauthorized,
+				"Only published blogs are available without signing in",
+			)
+			return
+		}
+
+		query.AuthorId = userId
+	}
+
+	if page := params.Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid page")
+			return
+		}
+
+		query.Page = p
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+
+		query.Limit = l
+	}
+
+	if err := utils.Validator.Struct(query); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid query: %v", errors),
+		)
+		return
+	}
+
+	if query.Cursor != "" {
+		if query.SortBy == "relevance" {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"Cursor pagination is not supported when sorting by relevance",
+			)
+			return
+		}
+
+		if _, err := utils.DecodeCursor(query.Cursor); err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	// Cursor pages can't be counted from the total, so one more blog than the
+	// limit is fetched to tell whether another page follows.
+	fetch := query
+	if query.Cursor != "" {
+		fetch.Limit++
 	}
 
-	blogs, err := h.store.GetBlogs(query)
+	blogs, total, err := h.store.GetBlogs(fetch)
 	if err != nil {
 		utils.WriteErrorInResponse(
 			w,
@@ -172,17 +533,132 @@ func (h *Handler) getBlogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(blogs) == 0 {
+	if blogs == nil {
+		blogs = []types_blog.Blog{}
+	}
+
+	hasMore := query.Page*query.Limit < total && query.SortBy != "relevance"
+	if query.Cursor != "" {
+		hasMore = len(blogs) > query.Limit
+		if hasMore {
+			blogs = blogs[:query.Limit]
+		}
+	}
+
+	payload := types_blog.BlogSearchResult{
+		Result:    blogs,
+		Total:     total,
+		Page:      query.Page,
+		PageCount: (total + query.Limit - 1) / query.Limit,
+	}
+
+	links := []string{}
+
+	if hasMore && len(blogs) > 0 {
+		last := blogs[len(blogs)-1]
+		payload.NextCursor = utils.EncodeCursor(utils.Cursor{
+			Value: blogSortValue(last, query.SortBy),
+			Id:    last.Id,
+		})
+	}
+
+	if query.Cursor != "" {
+		if payload.NextCursor != "" {
+			links = append(links, blogsLink(r, "next", "cursor", payload.NextCursor))
+		}
+	} else {
+		if query.Page < payload.PageCount {
+			links = append(links, blogsLink(r, "next", "page", strconv.Itoa(query.Page+1)))
+		}
+
+		if query.Page > 1 {
+			links = append(links, blogsLink(r, "prev", "page", strconv.Itoa(query.Page-1)))
+		}
+
+		links = append(links, blogsLink(r, "first", "page", "1"))
+
+		if payload.PageCount > 0 {
+			links = append(links, blogsLink(r, "last", "page", strconv.Itoa(payload.PageCount)))
+		}
+	}
+
+	headers := map[string]string{}
+	if len(links) > 0 {
+		headers["Link"] = strings.Join(links, ", ")
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, &headers)
+}
+
+func (h *Handler) getFeed(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return
+	}
+
+	params := r.URL.Query()
+
+	query := types_blog.FeedQuery{
+		UserId: userId.(string),
+		Cursor: params.Get("cursor"),
+		Limit:  20,
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+
+		query.Limit = l
+	}
+
+	if err := utils.Validator.Struct(query); err != nil {
+		errors := err.(validator.ValidationErrors)
 		utils.WriteErrorInResponse(
 			w,
-			http.StatusNotFound,
-			"No blog found",
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid query: %v", errors),
+		)
+		return
+	}
+
+	if query.Cursor != "" {
+		if _, err := utils.DecodeCursor(query.Cursor); err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	blogs, err := h.store.GetFeed(query)
+	if err != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusInternalServerError,
+			"An error occurred",
 		)
 		return
 	}
 
-	payload := map[string][]types_blog.Blog{
-		"result": blogs,
+	if blogs == nil {
+		blogs = []types_blog.Blog{}
+	}
+
+	payload := types_blog.FeedResult{Result: blogs}
+
+	if len(blogs) == query.Limit {
+		last := blogs[len(blogs)-1]
+		payload.NextCursor = utils.EncodeCursor(utils.Cursor{
+			Value: blogSortValue(last, "publishedAt"),
+			Id:    last.Id,
+		})
 	}
 
 	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
@@ -202,6 +678,10 @@ func (h *Handler) getBlog(w http.ResponseWriter, r *http.Request) {
 
 	b, err := h.store.GetBlogBySlug(slug)
 	if err != nil || b == nil {
+		if h.redirectOldSlug(w, r, slug) {
+			return
+		}
+
 		utils.WriteErrorInResponse(
 			w,
 			http.StatusNotFound,
@@ -210,9 +690,75 @@ func (h *Handler) getBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.canRead(w, r, b) {
+		return
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	if b.Status == types_blog.BlogStatusPublished && blogRole(b, userId) == "" {
+		h.views.Record(b.Id, h.views.visitorId(r))
+	}
+
+	b.Series = h.seriesNavigation(r, b)
+
 	utils.WriteJSONInResponse(w, http.StatusOK, b, nil)
 }
 
+func (h *Handler) getContent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug, ok := vars["slug"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog slug not found",
+		)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "html"
+	}
+
+	if format != "html" && format != "md" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid content format")
+		return
+	}
+
+	b, err := h.store.GetBlogBySlug(slug)
+	if err != nil || b == nil {
+		if h.redirectOldSlug(w, r, slug) {
+			return
+		}
+
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canRead(w, r, b) {
+		return
+	}
+
+	rendered, toc, body, ok := h.renderContent(b)
+	if !ok {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog content not found")
+		return
+	}
+
+	payload := types_blog.BlogContent{
+		Format:  format,
+		Content: rendered,
+		Toc:     toc,
+	}
+
+	if format == "md" {
+		payload.Content = body
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
 func (h *Handler) updateBlog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	blogId, ok := vars["id"]
@@ -247,6 +793,10 @@ func (h *Handler) updateBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.canEdit(w, r, b) {
+		return
+	}
+
 	updatedDate := time.Now()
 	updatePayload := types_blog.UpdateBlogPayload{
 		Slug:        b.Slug,
@@ -254,29 +804,44 @@ func (h *Handler) updateBlog(w http.ResponseWriter, r *http.Request) {
 		Description: b.Description,
 		PictureName: b.PictureName,
 		MDFilename:  b.MDFilename,
+		Visibility:  b.Visibility,
+		CategoryId:  b.CategoryId,
+		Tags:        payload.Tags,
 		UpdatedAt:   updatedDate,
 	}
 
 	if payload.Title != "" {
-		newSlug := utils.CreateSlug(payload.Title)
+		updatePayload.Title = payload.Title
+		updatePayload.Slug = h.uniqueSlug(payload.Title, b.Id)
+	}
 
-		if b, _ := h.store.GetBlogBySlug(newSlug); b != nil {
-			utils.WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				"Another blog with that title already exists",
-			)
+	if payload.Slug != "" {
+		slug, err := h.customSlug(payload.Slug, b.Id)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
-		updatePayload.Title = payload.Title
-		updatePayload.Slug = newSlug
+		updatePayload.Slug = slug
 	}
 
 	if payload.Description != "" {
 		updatePayload.Description = payload.Description
 	}
 
+	if payload.Visibility != "" {
+		updatePayload.Visibility = payload.Visibility
+	}
+
+	if payload.CategoryId != "" {
+		if c, _ := h.store.GetCategoryById(payload.CategoryId); c == nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Category doesn't exist")
+			return
+		}
+
+		updatePayload.CategoryId = payload.CategoryId
+	}
+
 	if payload.PictureName != "" {
 		isPictureExists, err := utils.PathExists(
 			fmt.Sprintf("%s/%s", h.imageUploadDir, payload.PictureName),
@@ -327,11 +892,29 @@ func (h *Handler) updateBlog(w http.ResponseWriter, r *http.Request) {
 		updatePayload.MDFilename = payload.MDFilename
 	}
 
+	content, found := h.blogContent(b)
+	updatePayload.PreviousContent = content
+	if updatePayload.MDFilename != b.MDFilename {
+		raw, err := os.ReadFile(
+			filepath.Join(h.mdFileUploadDir, filepath.Base(updatePayload.MDFilename)),
+		)
+		content, found = string(raw), err == nil
+	}
+
+	updatePayload.Stats = b.Stats
+	if found {
+		updatePayload.Content = &content
+		updatePayload.Stats = readingStats(content)
+	}
+	updatePayload.EditorId, _ = r.Context().Value("userId").(string)
+
 	if err := h.store.UpdateBlog(b.Id, updatePayload); err != nil {
 		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
 		return
 	}
 
+	h.related.invalidate()
+
 	utils.WriteJSONInResponse(
 		w,
 		http.StatusOK,
@@ -342,6 +925,646 @@ func (h *Handler) updateBlog(w http.ResponseWriter, r *http.Request) {
 	)
 }
 
+func (h *Handler) publishBlog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return
+	}
+
+	var payload types_blog.PublishBlogPayload
+	if r.ContentLength != 0 {
+		if err := utils.ParseJSONFromRequest(r, &payload); err != nil && err != io.EOF {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid publish payload")
+			return
+		}
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canEdit(w, r, b) {
+		return
+	}
+
+	now := time.Now()
+	status := types_blog.UpdateBlogStatusPayload{
+		Status:      types_blog.BlogStatusPublished,
+		PublishedAt: b.PublishedAt,
+		ExpiresAt:   payload.ExpiresAt,
+	}
+
+	if payload.ScheduledAt != nil && payload.ScheduledAt.After(now) {
+		status.Status = types_blog.BlogStatusScheduled
+		status.ScheduledAt = payload.ScheduledAt
+	} else if status.PublishedAt == nil {
+		status.PublishedAt = &now
+	}
+
+	if !isValidExpiry(status, now) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The expiry time must be after the publish time",
+		)
+		return
+	}
+
+	if err := h.store.UpdateBlogStatus(b.Id, status); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.related.invalidate()
+
+	message := fmt.Sprintf("Blog with id %s has been published successfully", b.Id)
+	if status.Status == types_blog.BlogStatusScheduled {
+		message = fmt.Sprintf(
+			"Blog with id %s has been scheduled to be published at %s",
+			b.Id,
+			status.ScheduledAt.Format(time.RFC3339),
+		)
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, map[string]string{"message": message}, nil)
+}
+
+func (h *Handler) unpublishBlog(w http.ResponseWriter, r *http.Request) {
+	h.changeBlogStatus(w, r, types_blog.BlogStatusDraft)
+}
+
+func (h *Handler) archiveBlog(w http.ResponseWriter, r *http.Request) {
+	h.changeBlogStatus(w, r, types_blog.BlogStatusArchived)
+}
+
+func (h *Handler) changeBlogStatus(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canEdit(w, r, b) {
+		return
+	}
+
+	err = h.store.UpdateBlogStatus(b.Id, types_blog.UpdateBlogStatusPayload{
+		Status:      status,
+		PublishedAt: b.PublishedAt,
+		ExpiresAt:   b.ExpiresAt,
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.related.invalidate()
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Blog with id %s has been moved to %s", b.Id, status),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) toggleReaction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return
+	}
+
+	var payload types_blog.ReactionPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid reaction payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil || b.Status != types_blog.BlogStatusPublished {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	userId := r.Context().Value("userId").(string)
+
+	active, err := h.store.ToggleReaction(b.Id, userId, payload.Reaction)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	reactions, err := h.store.GetReactionCounts(b.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, types_blog.ReactionResult{
+		Reaction:  payload.Reaction,
+		Active:    active,
+		Reactions: reactions,
+	}, nil)
+}
+
+func (h *Handler) getRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canRead(w, r, b) || !h.canEdit(w, r, b) {
+		return
+	}
+
+	revisions, err := h.store.GetBlogRevisions(b.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_blog.BlogRevision{
+		"result": revisions,
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) getRevision(w http.ResponseWriter, r *http.Request) {
+	revision, _, ok := h.getRevisionFromVars(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, revision, nil)
+}
+
+func (h *Handler) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return
+	}
+
+	params := r.URL.Query()
+
+	from, err := strconv.Atoi(params.Get("from"))
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid from revision")
+		return
+	}
+
+	to, err := strconv.Atoi(params.Get("to"))
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid to revision")
+		return
+	}
+
+	mode := params.Get("mode")
+	if mode == "" {
+		mode = "unified"
+	}
+
+	if mode != "unified" && mode != "word" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid diff mode")
+		return
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canRead(w, r, b) || !h.canEdit(w, r, b) {
+		return
+	}
+
+	fromRevision, err := h.store.GetBlogRevision(blogId, from)
+	if err != nil || fromRevision == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Revision not found")
+		return
+	}
+
+	toRevision, err := h.store.GetBlogRevision(blogId, to)
+	if err != nil || toRevision == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Revision not found")
+		return
+	}
+
+	diff := types_blog.BlogRevisionDiff{
+		From:   from,
+		To:     to,
+		Mode:   mode,
+		Fields: map[string]types_blog.BlogFieldChange{},
+	}
+
+	fields := map[string][2]string{
+		"title":       {fromRevision.Title, toRevision.Title},
+		"description": {fromRevision.Description, toRevision.Description},
+		"slug":        {fromRevision.Slug, toRevision.Slug},
+		"pictureName": {fromRevision.PictureName, toRevision.PictureName},
+		"mdFilename":  {fromRevision.MDFilename, toRevision.MDFilename},
+	}
+
+	for field, values := range fields {
+		if values[0] != values[1] {
+			diff.Fields[field] = types_blog.BlogFieldChange{From: values[0], To: values[1]}
+		}
+	}
+
+	if mode == "word" {
+		diff.Words, err = utils.WordDiff(fromRevision.Content, toRevision.Content)
+	} else {
+		diff.Unified, err = utils.UnifiedDiff(
+			fromRevision.Content,
+			toRevision.Content,
+			fmt.Sprintf("revision %d", from),
+			fmt.Sprintf("revision %d", to),
+			3,
+		)
+	}
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, diff, nil)
+}
+
+func (h *Handler) restoreRevision(w http.ResponseWriter, r *http.Request) {
+	revision, b, ok := h.getRevisionFromVars(w, r)
+	if !ok {
+		return
+	}
+
+	// Another blog may have taken the slug since this revision was made.
+	slug := revision.Slug
+	if slug != b.Slug && h.slugTaken(slug, b.Id) {
+		slug = h.uniqueSlug(slug, b.Id)
+	}
+
+	previousContent, _ := h.blogContent(b)
+
+	mdFilename := revision.MDFilename
+	if h.readMdFile(mdFilename) != revision.Content {
+		mdFilename = fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(revision.MDFilename))
+
+		err := os.MkdirAll(h.mdFileUploadDir, os.ModePerm)
+		if err == nil {
+			err = os.WriteFile(
+				filepath.Join(h.mdFileUploadDir, mdFilename),
+				[]byte(revision.Content),
+				0644,
+			)
+		}
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+			return
+		}
+	}
+
+	pictureName := revision.PictureName
+	isPictureExists, err := utils.PathExists(filepath.Join(h.imageUploadDir, pictureName))
+	if err != nil || !isPictureExists {
+		pictureName = b.PictureName
+	}
+
+	editorId, _ := r.Context().Value("userId").(string)
+
+	err = h.store.UpdateBlog(b.Id, types_blog.UpdateBlogPayload{
+		Slug:            slug,
+		Title:           revision.Title,
+		Description:     revision.Description,
+		PictureName:     pictureName,
+		MDFilename:      mdFilename,
+		Visibility:      b.Visibility,
+		CategoryId:      b.CategoryId,
+		UpdatedAt:       time.Now(),
+		Content:         &revision.Content,
+		PreviousContent: previousContent,
+		EditorId:        editorId,
+		Stats:           readingStats(revision.Content),
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.related.invalidate()
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf(
+				"Blog with id %s has been restored to revision %d successfully",
+				b.Id,
+				revision.Revision,
+			),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) getRevisionFromVars(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*types_blog.BlogRevision, *types_blog.Blog, bool) {
+	vars := mux.Vars(r)
+	blogId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Blog id not found",
+		)
+		return nil, nil, false
+	}
+
+	rev, err := strconv.Atoi(vars["rev"])
+	if err != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Invalid revision number",
+		)
+		return nil, nil, false
+	}
+
+	b, err := h.store.GetBlogById(blogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return nil, nil, false
+	}
+
+	if !h.canRead(w, r, b) || !h.canEdit(w, r, b) {
+		return nil, nil, false
+	}
+
+	revision, err := h.store.GetBlogRevision(b.Id, rev)
+	if err != nil || revision == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Revision not found")
+		return nil, nil, false
+	}
+
+	return revision, b, true
+}
+
+func (h *Handler) getTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.store.GetTags()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_blog.Tag{
+		"result": tags,
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) renameTag(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can manage tags")
+		return
+	}
+
+	var payload types_blog.RenameTagPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid tag payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	tag, err := h.store.GetTagBySlug(mux.Vars(r)["tag"])
+	if err != nil || tag == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	name := strings.TrimSpace(payload.Name)
+	slug := utils.CreateSlug(name)
+	if slug == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid tag name")
+		return
+	}
+
+	if t, _ := h.store.GetTagBySlug(slug); t != nil && t.Id != tag.Id {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another tag with that name already exists, merge them instead",
+		)
+		return
+	}
+
+	if err := h.store.RenameTag(tag.Id, name, slug); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.related.invalidate()
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		types_blog.Tag{Id: tag.Id, Name: name, Slug: slug},
+		nil,
+	)
+}
+
+func (h *Handler) mergeTags(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can manage tags")
+		return
+	}
+
+	var payload types_blog.MergeTagPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid tag payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	source, err := h.store.GetTagBySlug(mux.Vars(r)["tag"])
+	if err != nil || source == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	target, err := h.store.GetTagBySlug(utils.CreateSlug(payload.Into))
+	if err != nil || target == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Tag to merge into not found")
+		return
+	}
+
+	if source.Id == target.Id {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Cannot merge a tag into itself")
+		return
+	}
+
+	if err := h.store.MergeTags(source.Id, target.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.related.invalidate()
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf(
+				"Tag %s has been merged into %s successfully",
+				source.Slug,
+				target.Slug,
+			),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) getCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.store.GetCategories()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_blog.Category{
+		"result": buildCategoryTree(categories, ""),
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) createCategory(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can manage categories")
+		return
+	}
+
+	var payload types_blog.CreateCategoryPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid category payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	payload.Slug = utils.CreateSlug(payload.Name)
+	if payload.Slug == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid category name")
+		return
+	}
+
+	if c, _ := h.store.GetCategoryBySlug(payload.Slug); c != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another category with that name already exists",
+		)
+		return
+	}
+
+	if payload.ParentId != "" {
+		if c, _ := h.store.GetCategoryById(payload.ParentId); c == nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Parent category doesn't exist")
+			return
+		}
+	}
+
+	c, err := h.store.CreateCategory(payload)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusCreated, c, nil)
+}
+
 func (h *Handler) deleteBlog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	blogId, ok := vars["id"]
@@ -364,11 +1587,17 @@ func (h *Handler) deleteBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.canManage(w, r, b, "Only the owner of the blog can delete it") {
+		return
+	}
+
 	if err := h.store.DeleteBlogById(b.Id); err != nil {
 		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
 		return
 	}
 
+	h.related.invalidate()
+
 	utils.WriteJSONInResponse(
 		w,
 		http.StatusOK,
@@ -378,3 +1607,108 @@ func (h *Handler) deleteBlog(w http.ResponseWriter, r *http.Request) {
 		nil,
 	)
 }
+
+func blogSortValue(b types_blog.Blog, sortBy string) string {
+	switch sortBy {
+	case "title":
+		return b.Title
+	case "updatedAt":
+		return b.UpdatedAt.Format(time.RFC3339Nano)
+	case "publishedAt":
+		if b.PublishedAt != nil {
+			return b.PublishedAt.Format(time.RFC3339Nano)
+		}
+		return b.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return b.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func blogsLink(r *http.Request, rel string, key string, value string) string {
+	u := *r.URL
+	params := u.Query()
+
+	if key == "cursor" {
+		params.Del("page")
+	} else {
+		params.Del("cursor")
+	}
+
+	params.Set(key, value)
+	u.RawQuery = params.Encode()
+
+	return fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel)
+}
+
+func (h *Handler) canRead(w http.ResponseWriter, r *http.Request, b *types_blog.Blog) bool {
+	return CanRead(w, r, b)
+}
+
+// CanRead reports whether the user on the request may read the blog, writing
+// the response that hides it when they may not: drafts are only readable by
+// their authors and members-only blogs need a signed in user.
+func CanRead(w http.ResponseWriter, r *http.Request, b *types_blog.Blog) bool {
+	userId, _ := r.Context().Value("userId").(string)
+	if b.Status != types_blog.BlogStatusPublished && blogRole(b, userId) == "" {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusNotFound,
+			"Blog not found",
+		)
+		return false
+	}
+
+	if b.Visibility == types_blog.BlogVisibilityMembers && userId == "" {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusUnauthorized,
+			"This blog is only available to members",
+		)
+		return false
+	}
+
+	return true
+}
+
+func buildCategoryTree(categories []types_blog.Category, parentId string) []types_blog.Category {
+	tree := []types_blog.Category{}
+
+	for _, c := range categories {
+		if c.ParentId != parentId {
+			continue
+		}
+
+		c.Children = buildCategoryTree(categories, c.Id)
+		tree = append(tree, c)
+	}
+
+	return tree
+}
+
+// blogContent prefers the content stored with the blog and falls back to the
+// markdown file for blogs created before it was stored.
+func (h *Handler) blogContent(b *types_blog.Blog) (string, bool) {
+	return loadContent(h.store, h.mdFileUploadDir, b.Id, b.MDFilename)
+}
+
+func (h *Handler) readMdFile(filename string) string {
+	content, err := os.ReadFile(filepath.Join(h.mdFileUploadDir, filepath.Base(filename)))
+	if err != nil {
+		return ""
+	}
+
+	return string(content)
+}
+
+func isValidExpiry(status types_blog.UpdateBlogStatusPayload, now time.Time) bool {
+	if status.ExpiresAt == nil {
+		return true
+	}
+
+	publishAt := now
+	if status.ScheduledAt != nil {
+		publishAt = *status.ScheduledAt
+	}
+
+	return status.ExpiresAt.After(publishAt)
+}
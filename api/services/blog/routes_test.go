@@ -2,11 +2,19 @@ package blog
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"math/rand"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -20,7 +28,11 @@ import (
 )
 
 func TestBlogService(t *testing.T) {
-	userStore := MockUserStore{}
+	userStore := MockUserStore{
+		DefaultUsers: []types_user.User{
+			{Id: "99", Username: "admin", Role: types_user.UserRoleAdmin},
+		},
+	}
 	blogStore := MockBlogStore{
 		DefaultBlogs: []types_blog.Blog{
 			{
@@ -30,6 +42,7 @@ func TestBlogService(t *testing.T) {
 				Slug:        "blog1",
 				PictureName: "blog1-pic.jpg",
 				MDFilename:  "blog1.md",
+				Status:      types_blog.BlogStatusPublished,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			},
@@ -40,6 +53,10 @@ func TestBlogService(t *testing.T) {
 				Slug:        "blog2",
 				PictureName: "blog2-pic.jpg",
 				MDFilename:  "blog2.md",
+				AuthorId:    "20",
+				Authors:     []types_blog.BlogAuthor{{UserId: "20", Role: "owner"}},
+				Tags:        mockTags([]string{"Go", "Web"}),
+				Status:      types_blog.BlogStatusPublished,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			},
@@ -50,15 +67,73 @@ func TestBlogService(t *testing.T) {
 				Slug:        "blog3",
 				PictureName: "blog3-pic.jpg",
 				MDFilename:  "blog3.md",
+				CategoryId:  "9f3f6a52-6f0b-4c8e-9a4e-4f4c1c2d0b02",
+				Tags:        mockTags([]string{"Go"}),
+				Status:      types_blog.BlogStatusPublished,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			},
+			{
+				Id:          "4",
+				Title:       "Members Blog",
+				Description: "Members Blog",
+				Slug:        "members-blog",
+				PictureName: "blog4-pic.jpg",
+				MDFilename:  "blog4.md",
+				Visibility:  types_blog.BlogVisibilityMembers,
+				Status:      types_blog.BlogStatusPublished,
 				CreatedAt:   time.Now(),
 				UpdatedAt:   time.Now(),
 			},
 		},
+		Follows: map[string][]string{"10": {"20"}},
+		Categories: []types_blog.Category{
+			{
+				Id:   "9f3f6a52-6f0b-4c8e-9a4e-4f4c1c2d0b01",
+				Name: "Programming",
+				Slug: "programming",
+			},
+			{
+				Id:       "9f3f6a52-6f0b-4c8e-9a4e-4f4c1c2d0b02",
+				Name:     "Golang",
+				Slug:     "golang",
+				ParentId: "9f3f6a52-6f0b-4c8e-9a4e-4f4c1c2d0b01",
+			},
+		},
+		Revisions: map[string][]types_blog.BlogRevision{
+			"3": {
+				{
+					Id:          "1",
+					BlogId:      "3",
+					Revision:    1,
+					Title:       "Blog3 Draft",
+					Description: "Blog3",
+					Slug:        "blog3-draft",
+					PictureName: "test.jpg",
+					MDFilename:  "test.md",
+					Content:     "# HELLO\n",
+					CreatedAt:   time.Now(),
+				},
+				{
+					Id:          "2",
+					BlogId:      "3",
+					Revision:    2,
+					Title:       "Blog3",
+					Description: "Blog3",
+					Slug:        "blog3",
+					PictureName: "blog3-pic.jpg",
+					MDFilename:  "blog3.md",
+					Content:     "# HELLO WORLD\n",
+					CreatedAt:   time.Now(),
+				},
+			},
+		},
 	}
 
 	mdFileUploadDir := "testuploads/blogs/mds"
 	imageUploadDir := "testuploads/blogs/images"
-	handler := NewHandler(&blogStore, &userStore, mdFileUploadDir, imageUploadDir)
+	viewCounter := NewViewCounter(&blogStore, time.Minute, nil)
+	handler := NewHandler(&blogStore, &userStore, mdFileUploadDir, imageUploadDir, viewCounter)
 
 	t.Run("should get all blogs successfully", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/blog", nil)
@@ -78,7 +153,7 @@ func TestBlogService(t *testing.T) {
 		}
 	})
 
-	t.Run("should get no blogs at all because of wrong keyword", func(t *testing.T) {
+	t.Run("should get an empty page of blogs because of wrong keyword", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/blog?keyword=wrongkeyword", nil)
 		if err != nil {
 			t.Fatal(err)
@@ -91,8 +166,70 @@ func TestBlogService(t *testing.T) {
 
 		router.ServeHTTP(rr, req)
 
-		if rr.Code != http.StatusNotFound {
-			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Result == nil || len(res.Result) != 0 || res.Total != 0 {
+			t.Errorf("Expected an empty result, received %v", res)
+		}
+	})
+
+	t.Run("should paginate blogs with page and limit", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog?page=1&limit=2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Result) != 2 || res.Total != 3 || res.PageCount != 2 {
+			t.Errorf("Expected 2 blogs out of 3 in 2 pages, received %v", res)
+		}
+
+		if res.NextCursor == "" {
+			t.Error("Expected a next cursor, received none")
+		}
+
+		if link := rr.Header().Get("Link"); !strings.Contains(link, "page=2>; rel=\"next\"") {
+			t.Errorf("Expected a link to the next page, received %s", link)
+		}
+	})
+
+	t.Run("should fail to get blogs because of invalid sort field", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog?sortBy=slug", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
 		}
 	})
 
@@ -114,6 +251,54 @@ func TestBlogService(t *testing.T) {
 		}
 	})
 
+	t.Run("should fail to use a cursor when searching by relevance", func(t *testing.T) {
+		cursor := utils.EncodeCursor(utils.Cursor{Value: "Blog1", Id: "1"})
+
+		req, err := http.NewRequest("GET", "/blog?keyword=blog&cursor="+cursor, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should not give a next cursor on the last full page", func(t *testing.T) {
+		cursor := utils.EncodeCursor(utils.Cursor{
+			Value: time.Now().Format(time.RFC3339Nano),
+			Id:    "9",
+		})
+
+		req, err := http.NewRequest("GET", "/blog?tag=web&limit=1&cursor="+cursor, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Result) != 1 || res.NextCursor != "" {
+			t.Errorf("Expected one blog without a next cursor, received %+v", res)
+		}
+	})
+
 	t.Run("should get blog by slug", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/blog/blog1", nil)
 		if err != nil {
@@ -132,6 +317,65 @@ func TestBlogService(t *testing.T) {
 		}
 	})
 
+	t.Run("should hide members-only blogs from anonymous readers", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/members-blog", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected code %d, received %d", http.StatusUnauthorized, rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/blog?keyword=members", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Result) != 0 {
+			t.Errorf("Expected no blogs for anonymous readers, received %v", res.Result)
+		}
+	})
+
+	t.Run("should show members-only blogs to signed in readers", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/members-blog", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+	})
+
 	t.Run("should fail to get a blog because of wrong slug", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/blog/blog19", nil)
 		if err != nil {
@@ -321,6 +565,8 @@ func TestBlogService(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
 		rr := httptest.NewRecorder()
 		router := mux.NewRouter()
 
@@ -350,172 +596,3699 @@ func TestBlogService(t *testing.T) {
 			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
 		}
 	})
-}
+	t.Run("should get blogs of followed authors in the feed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/feed", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-type MockBlogStore struct {
-	DefaultBlogs []types_blog.Blog
-}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
 
-type MockGetBlogsResult struct {
-	Result map[string][]types_blog.Blog
-}
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
 
-type MockUserStore struct{}
+		router.HandleFunc("/blog/feed", handler.getFeed).Methods("GET")
 
-func (m *MockUserStore) GetUserById(id string) (*types_user.User, error) {
-	return nil, nil
-}
+		router.ServeHTTP(rr, req)
 
-func (m *MockUserStore) GetUserByUsername(username string) (*types_user.User, error) {
-	return nil, nil
-}
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
 
-func (m *MockUserStore) GetUserByEmail(email string) (*types_user.User, error) {
-	return nil, nil
-}
+		var res types_blog.FeedResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
 
-func (m *MockUserStore) GetUserByUsernameOrEmail(
-	username string,
-	email string,
-) (*types_user.User, error) {
-	return nil, nil
-}
+		if len(res.Result) != 1 || res.Result[0].Id != "2" {
+			t.Errorf("Expected only blog 2 in the feed, received %v", res.Result)
+		}
+	})
 
-func (m *MockUserStore) CreateUser(u types_user.RegisterUserPayload) (*types_user.User, error) {
-	return nil, nil
-}
+	t.Run("should get blogs co-authored by followed users in the feed", func(t *testing.T) {
+		blogStore.Follows["12"] = []string{"30"}
+		defer delete(blogStore.Follows, "12")
 
-func (m *MockUserStore) GetUsers(
-	query types_user.SearchUserQuery,
-) ([]types_user.User, error) {
-	return nil, nil
-}
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:       "together",
+			Title:    "Written Together",
+			Slug:     "written-together",
+			AuthorId: "40",
+			Authors: []types_blog.BlogAuthor{
+				{UserId: "40", Role: types_blog.BlogAuthorRoleOwner},
+				{UserId: "30", Role: types_blog.BlogAuthorRoleCoAuthor},
+			},
+			Status: types_blog.BlogStatusPublished,
+		})
+		defer blogStore.DeleteBlogById("together")
 
-func (m *MockUserStore) DeleteUserById(
-	id string,
-) error {
-	return nil
-}
+		req, err := http.NewRequest("GET", "/blog/feed", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-func (m *MockUserStore) DeleteUserByUsername(
-	username string,
-) error {
-	return nil
-}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "12"))
 
-func (m *MockBlogStore) GetBlogById(id string) (*types_blog.Blog, error) {
-	for i := range m.DefaultBlogs {
-		b := m.DefaultBlogs[i]
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
 
-		if b.Id == id {
-			return &b, nil
-		}
-	}
+		router.HandleFunc("/blog/feed", handler.getFeed).Methods("GET")
 
-	return nil, fmt.Errorf("Cannot find blog")
-}
+		router.ServeHTTP(rr, req)
 
-func (m *MockBlogStore) GetBlogBySlug(slug string) (*types_blog.Blog, error) {
-	for i := range m.DefaultBlogs {
-		b := m.DefaultBlogs[i]
+		var res types_blog.FeedResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
 
-		if b.Slug == slug {
-			return &b, nil
+		if len(res.Result) != 1 || res.Result[0].Id != "together" {
+			t.Errorf("Expected the co-authored blog in the feed, received %v", res.Result)
 		}
-	}
+	})
 
-	return nil, fmt.Errorf("Cannot find blog")
-}
+	t.Run("should get an empty feed when following nobody", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/feed", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-func (m *MockBlogStore) CreateBlog(b types_blog.CreateBlogPayload) (*types_blog.Blog, error) {
-	created := types_blog.Blog{
-		Id:          strconv.Itoa(rand.Int()),
-		Title:       b.Title,
-		Description: b.Description,
-		Slug:        b.Slug,
-		PictureName: b.PictureName,
-		MDFilename:  b.MDFilename,
-		CreatedAt:   time.Now(),
-	}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "11"))
 
-	m.DefaultBlogs = append(m.DefaultBlogs, created)
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
 
-	return &created, nil
-}
+		router.HandleFunc("/blog/feed", handler.getFeed).Methods("GET")
 
-func (m *MockBlogStore) GetBlogs(
-	query types_blog.SearchBlogQuery,
-) ([]types_blog.Blog, error) {
-	var res []types_blog.Blog
+		router.ServeHTTP(rr, req)
 
-	for i := range m.DefaultBlogs {
-		b := m.DefaultBlogs[i]
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
 
-		if len(query.Keyword) > 0 {
-			if strings.Contains(strings.ToLower(b.Description), query.Keyword) ||
-				strings.Contains(strings.ToLower(b.Title), query.Keyword) {
-				res = append(res, b)
-			}
-		} else {
+		var res types_blog.FeedResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Result) != 0 {
+			t.Errorf("Expected an empty feed, received %v", res.Result)
+		}
+	})
+	t.Run("should create blogs as drafts hidden from other readers", func(t *testing.T) {
+		payload := types_blog.CreateBlogPayload{
+			Title:       "My Draft Blog",
+			Description: "This is a draft blog",
+			PictureName: "test.jpg",
+			MDFilename:  "test.md",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var created types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+			t.Fatal(err)
+		}
+
+		if created.Status != types_blog.BlogStatusDraft {
+			t.Errorf("Expected a draft blog, received %s", created.Status)
+		}
+
+		req, err = http.NewRequest("GET", "/blog/"+created.Slug, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+		}
+
+		req, err = http.NewRequest("POST", "/blog/"+created.Id+"/publish", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/publish", handler.publishBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		published, _ := blogStore.GetBlogById(created.Id)
+		if published.Status != types_blog.BlogStatusPublished || published.PublishedAt == nil {
+			t.Errorf("Expected the blog to be published, received %v", published)
+		}
+	})
+
+	t.Run("should schedule a blog and publish it with the scheduler", func(t *testing.T) {
+		scheduledAt := time.Now().Add(time.Hour)
+		marshalled, err := json.Marshal(types_blog.PublishBlogPayload{ScheduledAt: &scheduledAt})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog/3/publish", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/publish", handler.publishBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		scheduled, _ := blogStore.GetBlogById("3")
+		if scheduled.Status != types_blog.BlogStatusScheduled {
+			t.Fatalf("Expected the blog to be scheduled, received %s", scheduled.Status)
+		}
+
+		past := time.Now().Add(-time.Minute)
+		scheduled.ScheduledAt = &past
+		blogStore.UpdateBlogStatus("3", types_blog.UpdateBlogStatusPayload{
+			Status:      scheduled.Status,
+			ScheduledAt: scheduled.ScheduledAt,
+		})
+
+		handler.NewScheduler(time.Minute).tick()
+
+		published, _ := blogStore.GetBlogById("3")
+		if published.Status != types_blog.BlogStatusPublished {
+			t.Errorf("Expected the blog to be published, received %s", published.Status)
+		}
+	})
+
+	t.Run("should unpublish expired blogs back to drafts", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Minute)
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:        "expired",
+			Title:     "Expired",
+			Slug:      "expired",
+			Status:    types_blog.BlogStatusPublished,
+			ExpiresAt: &expiresAt,
+		})
+		defer blogStore.DeleteBlogById("expired")
+
+		handler.related.set("1", []types_blog.Blog{{Id: "expired"}}, time.Now())
+
+		handler.NewScheduler(time.Minute).tick()
+
+		b, _ := blogStore.GetBlogById("expired")
+		if b.Status != types_blog.BlogStatusDraft || b.ExpiresAt != nil {
+			t.Errorf("Expected the blog to be a draft again, received %s", b.Status)
+		}
+
+		if _, ok := handler.related.get("1", time.Now()); ok {
+			t.Errorf("Expected the related blogs to be invalidated")
+		}
+	})
+
+	t.Run("should fail to change the status of someone else's blog", func(t *testing.T) {
+		routes := map[string]http.HandlerFunc{
+			"publish":   handler.publishBlog,
+			"unpublish": handler.unpublishBlog,
+			"archive":   handler.archiveBlog,
+		}
+
+		for action, handle := range routes {
+			req, err := http.NewRequest("POST", "/blog/3/"+action, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/blog/{id}/"+action, handle).Methods("POST")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusForbidden {
+				t.Errorf(
+					"Expected code %d for %s, received %d",
+					http.StatusForbidden,
+					action,
+					rr.Code,
+				)
+			}
+		}
+	})
+
+	t.Run("should fail to list drafts without signing in", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog?status=draft", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected code %d, received %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("should list revisions of a blog", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/3/revisions", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions", handler.getRevisions).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res map[string][]types_blog.BlogRevision
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res["result"]) != 2 {
+			t.Errorf("Expected 2 revisions, received %d", len(res["result"]))
+		}
+	})
+
+	t.Run("should diff two revisions of a blog", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/3/revisions/diff?from=1&to=2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions/diff", handler.diffRevisions).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var diff types_blog.BlogRevisionDiff
+		if err := json.NewDecoder(rr.Body).Decode(&diff); err != nil {
+			t.Fatal(err)
+		}
+
+		if diff.Fields["title"].From != "Blog3 Draft" || diff.Fields["title"].To != "Blog3" {
+			t.Errorf("Expected the title change in the diff, received %v", diff.Fields)
+		}
+
+		if !strings.Contains(diff.Unified, "-# HELLO\n+# HELLO WORLD") {
+			t.Errorf("Unexpected unified diff %q", diff.Unified)
+		}
+	})
+
+	t.Run("should fail to diff revisions because of invalid mode", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/3/revisions/diff?from=1&to=2&mode=char", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions/diff", handler.diffRevisions).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should fail to restore a revision without being an author", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/blog/3/revisions/1/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions/{rev}/restore", handler.restoreRevision).
+			Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should fail to read the revisions of a blog as a reader", func(t *testing.T) {
+		paths := map[string]http.HandlerFunc{
+			"/blog/{id}/revisions":       handler.getRevisions,
+			"/blog/{id}/revisions/{rev}": handler.getRevision,
+			"/blog/{id}/revisions/diff":  handler.diffRevisions,
+		}
+
+		for path, handle := range paths {
+			target := strings.NewReplacer("{id}", "3", "{rev}", "1").Replace(path) +
+				"?from=1&to=2"
+
+			req, err := http.NewRequest("GET", target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc(path, handle).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusForbidden {
+				t.Errorf(
+					"Expected code %d for %s, received %d",
+					http.StatusForbidden,
+					path,
+					rr.Code,
+				)
+			}
+		}
+	})
+
+	t.Run("should fail to read the revisions of a draft of another author", func(t *testing.T) {
+		draft, err := blogStore.CreateBlog(types_blog.CreateBlogPayload{
+			Title:    "Secret Draft",
+			Slug:     "secret-draft",
+			Status:   types_blog.BlogStatusDraft,
+			AuthorId: "20",
+			Content:  "# SECRET\n",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer blogStore.DeleteBlogById(draft.Id)
+
+		paths := map[string]http.HandlerFunc{
+			"/blog/{id}/revisions":       handler.getRevisions,
+			"/blog/{id}/revisions/{rev}": handler.getRevision,
+			"/blog/{id}/revisions/diff":  handler.diffRevisions,
+		}
+
+		for path, handle := range paths {
+			target := strings.NewReplacer("{id}", draft.Id, "{rev}", "1").Replace(path) +
+				"?from=1&to=1"
+
+			req, err := http.NewRequest("GET", target, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc(path, handle).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("Expected code %d for %s, received %d", http.StatusNotFound, path, rr.Code)
+			}
+		}
+	})
+
+	t.Run("should restore a blog to a previous revision", func(t *testing.T) {
+		taken, err := blogStore.CreateBlog(types_blog.CreateBlogPayload{
+			Title:    "Blog3 Draft",
+			Slug:     "blog3-draft",
+			Status:   types_blog.BlogStatusPublished,
+			AuthorId: "20",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer blogStore.DeleteBlogById(taken.Id)
+
+		req, err := http.NewRequest("POST", "/blog/3/revisions/1/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions/{rev}/restore", handler.restoreRevision).
+			Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		b, err := blogStore.GetBlogById("3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Title != "Blog3 Draft" || b.MDFilename != "test.md" {
+			t.Errorf("Expected the blog to be restored, received %v", b)
+		}
+
+		if b.Slug != "blog3-draft-2" {
+			t.Errorf("Expected the taken slug to be suffixed, received %s", b.Slug)
+		}
+
+		revisions := blogStore.Revisions["3"]
+		if len(revisions) != 3 || revisions[2].EditorId != "99" {
+			t.Errorf("Expected the restore to be recorded as a new revision")
+		}
+	})
+
+	t.Run("should fail to restore a missing revision", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/blog/3/revisions/10/restore", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/revisions/{rev}/restore", handler.restoreRevision).
+			Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("should filter blogs by tag", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog?tag=Go", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Total != 2 {
+			t.Errorf("Expected 2 blogs tagged go, received %d", res.Total)
+		}
+	})
+
+	t.Run("should filter blogs by a parent category", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog?category=programming", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.getBlogs).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res types_blog.BlogSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Total != 1 || res.Result[0].Id != "3" {
+			t.Errorf("Expected only blog 3 in the category, received %v", res.Result)
+		}
+	})
+
+	t.Run("should list tags with post counts", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/tags", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/tags", handler.getTags).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res map[string][]types_blog.Tag
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tag := range res["result"] {
+			if tag.Slug == "go" && tag.PostCount != 2 {
+				t.Errorf("Expected 2 posts tagged go, received %d", tag.PostCount)
+			}
+		}
+	})
+
+	t.Run("should fail to rename a tag without being an admin", func(t *testing.T) {
+		payload := types_blog.RenameTagPayload{Name: "Golang"}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PATCH", "/blog/tags/go", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/tags/{tag}", handler.renameTag).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should rename a tag as an admin", func(t *testing.T) {
+		payload := types_blog.RenameTagPayload{Name: "Golang"}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PATCH", "/blog/tags/go", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/tags/{tag}", handler.renameTag).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if tag, _ := blogStore.GetTagBySlug("golang"); tag == nil {
+			t.Errorf("Expected the tag to be renamed to golang")
+		}
+	})
+
+	t.Run("should merge a tag into another as an admin", func(t *testing.T) {
+		payload := types_blog.MergeTagPayload{Into: "golang"}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog/tags/web/merge", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/tags/{tag}/merge", handler.mergeTags).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if tag, _ := blogStore.GetTagBySlug("web"); tag != nil {
+			t.Errorf("Expected the web tag to be merged away")
+		}
+
+		b, _ := blogStore.GetBlogById("2")
+		if len(b.Tags) != 1 || b.Tags[0].Slug != "golang" {
+			t.Errorf("Expected blog 2 to only be tagged golang, received %v", b.Tags)
+		}
+	})
+
+	t.Run("should create a nested category and list the tree", func(t *testing.T) {
+		payload := types_blog.CreateCategoryPayload{
+			Name:     "Web Development",
+			ParentId: "9f3f6a52-6f0b-4c8e-9a4e-4f4c1c2d0b01",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog/categories", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/categories", handler.createCategory).Methods("POST")
+		router.HandleFunc("/blog/categories", handler.getCategories).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/blog/categories", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var res map[string][]types_blog.Category
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res["result"]) != 1 || len(res["result"][0].Children) != 2 {
+			t.Errorf("Expected one root category with two children, received %v", res["result"])
+		}
+	})
+
+	t.Run("should toggle a reaction on and off", func(t *testing.T) {
+		toggle := func() types_blog.ReactionResult {
+			payload := types_blog.ReactionPayload{Reaction: types_blog.BlogReactionInsightful}
+
+			marshalled, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("POST", "/blog/2/reactions", bytes.NewBuffer(marshalled))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/blog/{id}/reactions", handler.toggleReaction).Methods("POST")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+
+			var res types_blog.ReactionResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			return res
+		}
+
+		res := toggle()
+		if !res.Active || res.Reactions[types_blog.BlogReactionInsightful] != 1 {
+			t.Errorf("Expected the reaction to be added, received %v", res)
+		}
+
+		res = toggle()
+		if res.Active || res.Reactions[types_blog.BlogReactionInsightful] != 0 {
+			t.Errorf("Expected the reaction to be removed, received %v", res)
+		}
+	})
+
+	t.Run("should fail to react with an unknown reaction", func(t *testing.T) {
+		marshalled, err := json.Marshal(types_blog.ReactionPayload{Reaction: "angry"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog/2/reactions", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}/reactions", handler.toggleReaction).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should count one view per visitor per day", func(t *testing.T) {
+		view := func(remoteAddr string) {
+			req, err := http.NewRequest("GET", "/blog/blog2", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.RemoteAddr = remoteAddr
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+		}
+
+		view("10.0.0.1:5000")
+		view("10.0.0.1:5001")
+		viewCounter.flush()
+
+		view("10.0.0.1:5002")
+		view("10.0.0.2:5000")
+		viewCounter.flush()
+
+		b, err := blogStore.GetBlogById("2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.ViewCount != 2 {
+			t.Errorf("Expected 2 views, received %d", b.ViewCount)
+		}
+	})
+
+	t.Run("should only trust forwarded addresses from trusted proxies", func(t *testing.T) {
+		proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.168.1.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counter := NewViewCounter(&blogStore, time.Minute, proxies)
+
+		clientIP := func(remoteAddr string, forwarded string) string {
+			req, err := http.NewRequest("GET", "/blog/blog2", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.RemoteAddr = remoteAddr
+			if forwarded != "" {
+				req.Header.Set("X-Forwarded-For", forwarded)
+			}
+
+			return counter.clientIP(req)
+		}
+
+		for _, c := range []struct {
+			remoteAddr string
+			forwarded  string
+			expected   string
+		}{
+			{"203.0.113.5:5000", "1.1.1.1", "203.0.113.5"},
+			{"10.0.0.1:5000", "1.1.1.1", "1.1.1.1"},
+			{"10.0.0.1:5000", "9.9.9.9, 1.1.1.1, 192.168.1.1", "1.1.1.1"},
+			{"192.168.1.1:5000", "not-an-ip", "192.168.1.1"},
+		} {
+			if ip := clientIP(c.remoteAddr, c.forwarded); ip != c.expected {
+				t.Errorf("Expected %s for %+v, received %s", c.expected, c, ip)
+			}
+		}
+
+		if _, err := ParseTrustedProxies("10.0.0.0/33"); err == nil {
+			t.Errorf("Expected an invalid proxy range to be rejected")
+		}
+	})
+
+	t.Run("should drop views past the pending limit", func(t *testing.T) {
+		counter := NewViewCounter(&blogStore, time.Minute, nil)
+		counter.maxPending = 2
+
+		counter.Record("2", "a")
+		counter.Record("2", "b")
+		counter.Record("2", "c")
+		counter.Record("2", "a")
+
+		if len(counter.pending) != 2 || counter.dropped != 1 {
+			t.Errorf("Expected 2 pending and 1 dropped views, received %v", counter.pending)
+		}
+	})
+
+	t.Run("should render the content of a blog", func(t *testing.T) {
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:          "5",
+			Title:       "Content Blog",
+			Slug:        "content-blog",
+			PictureName: "test.jpg",
+			MDFilename:  "test.md",
+			Visibility:  types_blog.BlogVisibilityPublic,
+			Status:      types_blog.BlogStatusPublished,
+			UpdatedAt:   time.Now(),
+		})
+
+		for format, expected := range map[string]string{
+			"html": "<h1 id=\"hello\">HELLO</h1>\n",
+			"md":   "# HELLO\n",
+		} {
+			req, err := http.NewRequest("GET", "/blog/content-blog/content?format="+format, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/blog/{slug}/content", handler.getContent).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+
+			var content types_blog.BlogContent
+			if err := json.NewDecoder(rr.Body).Decode(&content); err != nil {
+				t.Fatal(err)
+			}
+
+			if content.Content != expected {
+				t.Errorf("Expected %q content %q, received %q", format, expected, content.Content)
+			}
+
+			if len(content.Toc) != 1 || content.Toc[0].Anchor != "hello" {
+				t.Errorf("Unexpected table of contents %v", content.Toc)
+			}
+		}
+	})
+
+	t.Run("should fail to render content in an unknown format", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/content-blog/content?format=pdf", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/content", handler.getContent).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should fail to render content of a blog without its md file", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/blog2/content", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/content", handler.getContent).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("should create a blog from the front matter of the md file", func(t *testing.T) {
+		marshalled, err := json.Marshal(map[string]string{"mdFilename": "frontmatter.md"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var b types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Title != "Front Matter Blog" || b.Slug != "custom-front-matter" ||
+			b.PictureName != "test.jpg" || len(b.Tags) != 2 {
+			t.Errorf("Expected the blog to be filled from front matter, received %+v", b)
+		}
+
+		if b.Status != types_blog.BlogStatusDraft {
+			t.Errorf("Expected the blog to stay a draft, received %s", b.Status)
+		}
+
+		blogStore.DeleteBlogById(b.Id)
+
+		marshalled, err = json.Marshal(map[string]string{
+			"mdFilename": "frontmatter.md",
+			"status":     types_blog.BlogStatusPublished,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err = http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Status != types_blog.BlogStatusPublished || b.PublishedAt == nil ||
+			b.PublishedAt.Year() != 2024 {
+			t.Errorf("Expected the blog to be published at the front matter date")
+		}
+	})
+
+	t.Run("should fail to create a blog without front matter or fields", func(t *testing.T) {
+		marshalled, err := json.Marshal(map[string]string{"mdFilename": "test.md"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should create a blog from a single multipart request", func(t *testing.T) {
+		picture, err := os.ReadFile(filepath.Join(imageUploadDir, "test.jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+
+		err = writer.WriteField(
+			"metadata",
+			`{"title":"Multipart Blog","description":"One request","status":"published"}`,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files := map[string][]string{
+			"mdFile": {"multipart.md", "text/markdown", "# MULTIPART\n"},
+			"image":  {"multipart.jpg", "image/jpeg", string(picture)},
+		}
+
+		for field, file := range files {
+			header := textproto.MIMEHeader{}
+			header.Set(
+				"Content-Disposition",
+				fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, file[0]),
+			)
+			header.Set("Content-Type", file[1])
+
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			part.Write([]byte(file[2]))
+		}
+
+		writer.Close()
+
+		req, err := http.NewRequest("POST", "/blog", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var b types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		os.Remove(filepath.Join(mdFileUploadDir, b.MDFilename))
+		os.Remove(filepath.Join(imageUploadDir, b.PictureName))
+
+		if !strings.HasSuffix(b.MDFilename, "multipart.md") ||
+			!strings.HasSuffix(b.PictureName, "multipart.jpg") {
+			t.Fatalf("Expected the uploaded files to be used, received %+v", b)
+		}
+
+		req, err = http.NewRequest("GET", "/blog/"+b.Slug+"/content?format=md", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/content", handler.getContent).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var content types_blog.BlogContent
+		if err := json.NewDecoder(rr.Body).Decode(&content); err != nil {
+			t.Fatal(err)
+		}
+
+		if content.Content != "# MULTIPART\n" {
+			t.Errorf("Expected the stored content, received %q", content.Content)
+		}
+	})
+
+	t.Run("should suffix the slug of a blog with a duplicated title", func(t *testing.T) {
+		payload := types_blog.CreateBlogPayload{
+			Title:       "Blog2",
+			Description: "This is a test blog",
+			PictureName: "test.jpg",
+			MDFilename:  "test.md",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var b types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Slug != "blog2-2" {
+			t.Errorf("Expected slug %q, received %q", "blog2-2", b.Slug)
+		}
+	})
+
+	t.Run("should fail to create a blog with a reserved slug", func(t *testing.T) {
+		payload := types_blog.CreateBlogPayload{
+			Title:       "Reserved Blog",
+			Slug:        "Feed",
+			Description: "This is a test blog",
+			PictureName: "test.jpg",
+			MDFilename:  "test.md",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should reject an oversized image in a multipart request", func(t *testing.T) {
+		picture, err := os.ReadFile(filepath.Join(imageUploadDir, "test.jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		picture = append(picture, make([]byte, maxImageSizeInMB*1024*1024)...)
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+
+		files := map[string][]string{
+			"mdFile": {"oversized.md", "text/markdown", "# OVERSIZED\n"},
+			"image":  {"oversized.jpg", "image/jpeg", string(picture)},
+		}
+
+		for _, field := range []string{"mdFile", "image"} {
+			file := files[field]
+
+			header := textproto.MIMEHeader{}
+			header.Set(
+				"Content-Disposition",
+				fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, file[0]),
+			)
+			header.Set("Content-Type", file[1])
+
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			part.Write([]byte(file[2]))
+		}
+
+		writer.Close()
+
+		req, err := http.NewRequest("POST", "/blog", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+
+		entries, err := os.ReadDir(mdFileUploadDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), "oversized.md") {
+				t.Errorf("Expected the uploaded markdown to be removed, found %s", e.Name())
+			}
+		}
+	})
+
+	t.Run("should clear the content of a blog whose markdown is missing", func(t *testing.T) {
+		stats := types_blog.ReadingStats{WordCount: 42, ReadingTime: 1}
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:          "41",
+			Title:       "Missing Markdown",
+			Slug:        "missing-markdown",
+			PictureName: "test.jpg",
+			MDFilename:  "missing.md",
+			Visibility:  types_blog.BlogVisibilityPublic,
+			Status:      types_blog.BlogStatusDraft,
+			Stats:       stats,
+		})
+		defer blogStore.DeleteBlogById("41")
+
+		req, err := http.NewRequest("PATCH", "/blog/41", strings.NewReader(`{"title":"Renamed"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}", handler.updateBlog).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if content, err := blogStore.GetBlogContent("41"); err != nil || content != nil {
+			t.Errorf("Expected the content to be cleared, received %v", content)
+		}
+
+		b, err := blogStore.GetBlogById("41")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Stats != stats {
+			t.Errorf("Expected the reading stats to be kept, received %v", b.Stats)
+		}
+	})
+
+	t.Run("should redirect an old slug to the current one", func(t *testing.T) {
+		marshalled, err := json.Marshal(map[string]string{"slug": "renamed-content-blog"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PATCH", "/blog/5", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}", handler.updateBlog).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/blog/content-blog/content?format=md", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+		router.HandleFunc("/blog/{slug}/content", handler.getContent).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusMovedPermanently {
+			t.Fatalf("Expected code %d, received %d", http.StatusMovedPermanently, rr.Code)
+		}
+
+		expected := "/blog/renamed-content-blog/content?format=md"
+		if location := rr.Header().Get("Location"); location != expected {
+			t.Errorf("Expected redirect to %q, received %q", expected, location)
+		}
+	})
+
+	t.Run("should suffix the slug a renamed blog used to have", func(t *testing.T) {
+		payload := types_blog.CreateBlogPayload{
+			Title:       "Content Blog",
+			Description: "This is a test blog",
+			PictureName: "test.jpg",
+			MDFilename:  "test.md",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/blog", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog", handler.createBlog).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var b types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+		defer blogStore.DeleteBlogById(b.Id)
+
+		if b.Slug != "content-blog-2" {
+			t.Errorf("Expected slug %q, received %q", "content-blog-2", b.Slug)
+		}
+
+		if old, _ := blogStore.GetBlogByOldSlug("content-blog"); old == nil || old.Id != "5" {
+			t.Errorf("Expected the old slug to keep redirecting to blog 5")
+		}
+	})
+
+	t.Run("should report missing files and remove old orphan uploads", func(t *testing.T) {
+		mdDir := t.TempDir()
+		imageDir := t.TempDir()
+
+		old := time.Now().Add(-48 * time.Hour)
+
+		for _, name := range []string{"test.md", "orphan.md", "fresh.md"} {
+			path := filepath.Join(mdDir, name)
+			if err := os.WriteFile(path, []byte("# HELLO\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if name != "fresh.md" {
+				os.Chtimes(path, old, old)
+			}
+		}
+
+		vault := NewVault(&blogStore, mdDir, imageDir, 24*time.Hour, false)
+
+		report, err := vault.Check(time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(report.OrphanFiles) != 2 || report.RemovedFiles != 1 || report.PendingFiles != 1 {
+			t.Errorf("Unexpected orphan files %+v", report.OrphanFiles)
+		}
+
+		for name, expected := range map[string]bool{
+			"test.md":   true,
+			"orphan.md": false,
+			"fresh.md":  true,
+		} {
+			if exists, _ := utils.PathExists(filepath.Join(mdDir, name)); exists != expected {
+				t.Errorf("Expected %s to exist: %v", name, expected)
+			}
+		}
+
+		missingPicture := false
+		for _, f := range report.MissingFiles {
+			if f.BlogId == "3" && f.Kind == "image" {
+				missingPicture = true
+			}
+		}
+
+		if !missingPicture {
+			t.Errorf("Expected the missing picture of blog 3 to be reported")
+		}
+	})
+
+	t.Run("should keep the images linked from the content of blogs", func(t *testing.T) {
+		mdDir := t.TempDir()
+		imageDir := t.TempDir()
+
+		old := time.Now().Add(-48 * time.Hour)
+
+		md := "![a](/api/v1/blog/image/from%20md.png)\n"
+		if err := os.WriteFile(filepath.Join(mdDir, "test.md"), []byte(md), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		blogStore.setContent("vault-content", "![b](http://localhost/api/v1/blog/image/inline.png)")
+		defer delete(blogStore.Contents, "vault-content")
+
+		for _, name := range []string{"from md.png", "inline.png", "orphan.png"} {
+			path := filepath.Join(imageDir, name)
+			if err := os.WriteFile(path, []byte("image"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.Chtimes(path, old, old)
+		}
+
+		vault := NewVault(&blogStore, mdDir, imageDir, 24*time.Hour, false)
+
+		if _, err := vault.Check(time.Now()); err != nil {
+			t.Fatal(err)
+		}
+
+		for name, expected := range map[string]bool{
+			"from md.png": true,
+			"inline.png":  true,
+			"orphan.png":  false,
+		} {
+			if exists, _ := utils.PathExists(filepath.Join(imageDir, name)); exists != expected {
+				t.Errorf("Expected %s to exist: %v", name, expected)
+			}
+		}
+	})
+
+	t.Run("should serve the published blogs as an rss feed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/feed.rss", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/feed.{format:rss|atom|json}", handler.getSyndicationFeed).
+			Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var feed rssFeed
+		if err := xml.NewDecoder(rr.Body).Decode(&feed); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, item := range feed.Channel.Items {
+			if strings.Contains(item.Link, "members-blog") {
+				t.Errorf("Expected members only blogs to be left out of the feed")
+			}
+		}
+
+		if !slices.ContainsFunc(feed.Channel.Items, func(i rssItem) bool {
+			return i.Enclosure != nil && i.Enclosure.Type == "image/jpeg"
+		}) {
+			t.Errorf("Expected feed items with image enclosures, received %+v", feed.Channel.Items)
+		}
+	})
+
+	t.Run("should put the latest published blog first in the feed", func(t *testing.T) {
+		publishedAt := time.Now().Add(time.Minute)
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:          "old-draft",
+			Title:       "Old Draft",
+			Slug:        "old-draft",
+			Status:      types_blog.BlogStatusPublished,
+			CreatedAt:   time.Now().AddDate(-1, 0, 0),
+			UpdatedAt:   time.Now().AddDate(-1, 0, 0),
+			PublishedAt: &publishedAt,
+		})
+		defer blogStore.DeleteBlogById("old-draft")
+
+		req, err := http.NewRequest("GET", "/blog/feed.atom", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/feed.{format:rss|atom|json}", handler.getSyndicationFeed).
+			Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		var feed atomFeed
+		if err := xml.NewDecoder(rr.Body).Decode(&feed); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(feed.Entries) == 0 || !strings.HasSuffix(feed.Entries[0].Id, "/old-draft") {
+			t.Fatalf("Expected the latest published blog first, received %+v", feed.Entries)
+		}
+
+		if feed.Updated != publishedAt.UTC().Truncate(time.Second).Format(time.RFC3339) {
+			t.Errorf("Expected the feed to be dated by its latest entry, received %s", feed.Updated)
+		}
+	})
+
+	t.Run("should serve a full content json feed of a tag", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/feed.json?tag=golang&mode=full", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/feed.{format:rss|atom|json}", handler.getSyndicationFeed).
+			Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var feed jsonFeed
+		if err := json.NewDecoder(rr.Body).Decode(&feed); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(feed.Items) == 0 {
+			t.Fatalf("Expected tagged blogs in the feed")
+		}
+
+		withContent := 0
+		for _, item := range feed.Items {
+			if !slices.Contains(item.Tags, "Golang") {
+				t.Errorf("Expected only blogs tagged Golang, received %v", item.Tags)
+			}
+
+			if item.ContentHTML != "" {
+				withContent++
+			}
+		}
+
+		if withContent == 0 {
+			t.Errorf("Expected the full content of the blogs in the feed")
+		}
+	})
+
+	t.Run("should answer conditional atom feed requests with not modified", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.HandleFunc("/blog/feed.{format:rss|atom|json}", handler.getSyndicationFeed).
+			Methods("GET")
+
+		req, err := http.NewRequest("GET", "/blog/feed.atom", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		etag := rr.Header().Get("ETag")
+		if rr.Code != http.StatusOK || etag == "" {
+			t.Fatalf("Expected an atom feed with an etag, received %d", rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/blog/feed.atom", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("If-None-Match", etag)
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotModified {
+			t.Errorf("Expected code %d, received %d", http.StatusNotModified, rr.Code)
+		}
+	})
+
+	t.Run("should list the published blogs in the sitemap", func(t *testing.T) {
+		router := mux.NewRouter()
+		handler.RegisterSiteRoutes(router)
+
+		req, err := http.NewRequest("GET", "/sitemap.xml", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var set sitemapURLSet
+		if err := xml.NewDecoder(rr.Body).Decode(&set); err != nil {
+			t.Fatal(err)
+		}
+
+		total, _ := blogStore.CountSitemapEntries()
+		if len(set.URLs) != total+1 {
+			t.Errorf("Expected %d urls, received %d", total+1, len(set.URLs))
+		}
+
+		for _, u := range set.URLs {
+			if strings.Contains(u.Loc, "members-blog") {
+				t.Errorf("Expected members only blogs to be left out of the sitemap")
+			}
+		}
+	})
+
+	t.Run("should split a large sitemap into an index", func(t *testing.T) {
+		sitemapPageSize = 1
+		defer func() { sitemapPageSize = 50000 }()
+
+		router := mux.NewRouter()
+		handler.RegisterSiteRoutes(router)
+
+		req, err := http.NewRequest("GET", "/sitemap.xml", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		var index sitemapIndex
+		if err := xml.NewDecoder(rr.Body).Decode(&index); err != nil {
+			t.Fatal(err)
+		}
+
+		total, _ := blogStore.CountSitemapEntries()
+		if len(index.Sitemaps) != total+1 {
+			t.Fatalf("Expected %d sitemaps, received %d", total+1, len(index.Sitemaps))
+		}
+
+		seen := map[string]bool{}
+
+		for page := 1; page <= total+1; page++ {
+			req, err = http.NewRequest("GET", fmt.Sprintf("/sitemap-%d.xml", page), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			var set sitemapURLSet
+			if err := xml.NewDecoder(rr.Body).Decode(&set); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(set.URLs) != 1 {
+				t.Fatalf("Expected a single url on page %d, received %d", page, len(set.URLs))
+			}
+
+			seen[set.URLs[0].Loc] = true
+		}
+
+		if len(seen) != total+1 {
+			t.Errorf("Expected %d distinct urls across the pages, received %d", total+1, len(seen))
+		}
+
+		req, err = http.NewRequest("GET", fmt.Sprintf("/sitemap-%d.xml", total+2), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("should point crawlers to the sitemap", func(t *testing.T) {
+		router := mux.NewRouter()
+		handler.RegisterSiteRoutes(router)
+
+		req, err := http.NewRequest("GET", "/robots.txt", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "/sitemap.xml") {
+			t.Errorf("Expected robots.txt to reference the sitemap, received %q", rr.Body.String())
+		}
+	})
+
+	t.Run("should get the social card metadata of a blog", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/blog2/meta", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/meta", handler.getMeta).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var meta types_blog.BlogMeta
+		if err := json.NewDecoder(rr.Body).Decode(&meta); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(meta.Image, "http") ||
+			!strings.HasSuffix(meta.Image, "blog2-pic.jpg") {
+			t.Errorf("Expected an absolute image url, received %q", meta.Image)
+		}
+
+		if meta.OpenGraph["og:title"] != meta.Title ||
+			meta.Twitter["twitter:card"] != "summary_large_image" {
+			t.Errorf("Unexpected card metadata %v %v", meta.OpenGraph, meta.Twitter)
+		}
+	})
+	t.Run("should get the related blogs without the blog itself", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/blog2/related?limit=1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/related", handler.getRelated).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var related []types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&related); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(related) > 1 {
+			t.Errorf("Expected at most 1 related blog, received %d", len(related))
+		}
+
+		for _, b := range related {
+			if b.Slug == "blog2" {
+				t.Errorf("Expected the blog to be excluded from its related blogs")
+			}
+		}
+	})
+
+	t.Run("should fail to get the related blogs with an invalid limit", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/blog2/related?limit=100", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}/related", handler.getRelated).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should cache the related blogs until a blog changes", func(t *testing.T) {
+		router := mux.NewRouter()
+		router.HandleFunc("/blog/{slug}/related", handler.getRelated).Methods("GET")
+
+		fetch := func() {
+			req, err := http.NewRequest("GET", "/blog/blog2/related", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+		}
+
+		handler.related.invalidate()
+		calls := blogStore.RelatedCalls
+
+		fetch()
+		fetch()
+
+		if blogStore.RelatedCalls != calls+1 {
+			t.Errorf("Expected 1 store call, received %d", blogStore.RelatedCalls-calls)
+		}
+
+		handler.related.invalidate()
+		fetch()
+
+		if blogStore.RelatedCalls != calls+2 {
+			t.Errorf("Expected the cache to be recomputed after a change")
+		}
+	})
+	t.Run("should create a series and order its parts", func(t *testing.T) {
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs,
+			types_blog.Blog{
+				Id:          "6",
+				Title:       "Part Draft",
+				Description: "Part Draft",
+				Slug:        "part-draft",
+				AuthorId:    "20",
+				Status:      types_blog.BlogStatusDraft,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			},
+			types_blog.Blog{
+				Id:          "7",
+				Title:       "Part Intro",
+				Description: "Part Intro",
+				Slug:        "part-intro",
+				AuthorId:    "20",
+				Status:      types_blog.BlogStatusPublished,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			},
+		)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/blog/series", handler.createSeries).Methods("POST")
+		router.HandleFunc("/blog/series/{id}/parts", handler.addSeriesPart).Methods("POST")
+		router.HandleFunc("/blog/series/{id}/parts/{blogId}", handler.moveSeriesPart).
+			Methods("PATCH")
+
+		send := func(method string, path string, body string) *httptest.ResponseRecorder {
+			req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "20"))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := send("POST", "/blog/series", `{"title":"Go Tutorial","description":"In parts"}`)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+		}
+
+		var series types_blog.Series
+		if err := json.NewDecoder(rr.Body).Decode(&series); err != nil {
+			t.Fatal(err)
+		}
+
+		if series.Slug != "go-tutorial" || series.AuthorId != "20" {
+			t.Fatalf("Unexpected series %v", series)
+		}
+
+		for _, id := range []string{"2", "6", "7"} {
+			body := fmt.Sprintf(`{"blogId":"%s"}`, id)
+
+			rr = send("POST", "/blog/series/"+series.Id+"/parts", body)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+		}
+
+		rr = send("PATCH", "/blog/series/"+series.Id+"/parts/7", `{"position":1}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if err := json.NewDecoder(rr.Body).Decode(&series); err != nil {
+			t.Fatal(err)
+		}
+
+		order := []string{}
+		for _, p := range series.Parts {
+			order = append(order, p.Id)
+		}
+
+		if !slices.Equal(order, []string{"7", "2", "6"}) {
+			t.Errorf("Expected the parts in order [7 2 6], received %v", order)
+		}
+	})
+
+	t.Run("should fail to add a part to someone else's series", func(t *testing.T) {
+		series, _ := blogStore.GetSeriesBySlug("go-tutorial")
+
+		req, err := http.NewRequest(
+			"POST",
+			"/blog/series/"+series.Id+"/parts",
+			bytes.NewBufferString(`{"blogId":"3"}`),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/series/{id}/parts", handler.addSeriesPart).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should fail to reorder a series without all of its parts", func(t *testing.T) {
+		series, _ := blogStore.GetSeriesBySlug("go-tutorial")
+
+		req, err := http.NewRequest(
+			"PUT",
+			"/blog/series/"+series.Id+"/parts",
+			bytes.NewBufferString(`{"blogIds":["2","7"]}`),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "20"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/series/{id}/parts", handler.reorderSeries).Methods("PUT")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should list only the readable parts on the series page", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/series/go-tutorial", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/series/{slug}", handler.getSeries).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var series types_blog.Series
+		if err := json.NewDecoder(rr.Body).Decode(&series); err != nil {
+			t.Fatal(err)
+		}
+
+		if series.PartCount != 2 || len(series.Parts) != 2 {
+			t.Errorf("Expected the draft part to be hidden, received %v", series.Parts)
+		}
+	})
+
+	t.Run("should embed the series navigation in the blog", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/blog/blog2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{slug}", handler.getBlog).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var b types_blog.Blog
+		if err := json.NewDecoder(rr.Body).Decode(&b); err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Series == nil || b.Series.Position != 2 || b.Series.Total != 2 {
+			t.Fatalf("Unexpected series navigation %v", b.Series)
+		}
+
+		if b.Series.Previous == nil || b.Series.Previous.Slug != "part-intro" {
+			t.Errorf("Expected the previous part to be part-intro, received %v", b.Series.Previous)
+		}
+
+		if b.Series.Next != nil {
+			t.Errorf("Expected the draft part to be skipped, received %v", b.Series.Next)
+		}
+	})
+	t.Run("should add a co-authored blog to a series and drop it on delete", func(t *testing.T) {
+		blogStore.DefaultBlogs = append(blogStore.DefaultBlogs, types_blog.Blog{
+			Id:          "8",
+			Title:       "Part Together",
+			Description: "Part Together",
+			Slug:        "part-together",
+			AuthorId:    "30",
+			Authors: []types_blog.BlogAuthor{
+				{UserId: "30", Role: types_blog.BlogAuthorRoleOwner},
+				{UserId: "20", Role: types_blog.BlogAuthorRoleCoAuthor},
+			},
+			Status:    types_blog.BlogStatusPublished,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+
+		series, _ := blogStore.GetSeriesBySlug("go-tutorial")
+
+		router := mux.NewRouter()
+		router.HandleFunc("/blog/series/{id}/parts", handler.addSeriesPart).Methods("POST")
+		router.HandleFunc("/blog/{id}", handler.deleteBlog).Methods("DELETE")
+
+		send := func(userId string, method string, path string, body string) int {
+			req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			return rr.Code
+		}
+
+		code := send("20", "POST", "/blog/series/"+series.Id+"/parts", `{"blogId":"8"}`)
+		if code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, code)
+		}
+
+		if code := send("30", "DELETE", "/blog/8", ""); code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, code)
+		}
+
+		parts, _ := blogStore.GetSeriesParts(series.Id)
+		if len(parts) != 3 || slices.ContainsFunc(parts, func(b types_blog.Blog) bool {
+			return b.Id == "8"
+		}) {
+			t.Errorf("Expected the deleted blog to leave the series, received %v", parts)
+		}
+	})
+
+	t.Run("should fail to delete a blog without being its owner", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/blog/2", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "10"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/blog/{id}", handler.deleteBlog).Methods("DELETE")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should invite collaborators and let them edit by role", func(t *testing.T) {
+		userStore.DefaultUsers = append(userStore.DefaultUsers,
+			types_user.User{Id: "30", Username: "writer"},
+			types_user.User{Id: "31", Username: "reviewer"},
+		)
+
+		router := mux.NewRouter()
+		router.HandleFunc("/blog/invitations", handler.getInvitations).Methods("GET")
+		router.HandleFunc("/blog/invitations/{id}/accept", handler.acceptInvitation).
+			Methods("POST")
+		router.HandleFunc("/blog/{id}/invitations", handler.inviteAuthor).Methods("POST")
+		router.HandleFunc("/blog/{id}", handler.updateBlog).Methods("PATCH")
+
+		send := func(
+			userId string,
+			method string,
+			path string,
+			body string,
+		) *httptest.ResponseRecorder {
+			req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		rr := send("10", "POST", "/blog/2/invitations", `{"username":"writer","role":"coauthor"}`)
+		if rr.Code != http.StatusForbidden {
+			t.Fatalf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+
+		invitees := map[string]string{"writer": "coauthor", "reviewer": "reviewer"}
+		for username, role := range invitees {
+			body := fmt.Sprintf(`{"username":"%s","role":"%s"}`, username, role)
+
+			rr = send("20", "POST", "/blog/2/invitations", body)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected code %d, received %d", http.StatusCreated, rr.Code)
+			}
+		}
+
+		rr = send("20", "POST", "/blog/2/invitations", `{"username":"writer","role":"coauthor"}`)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+
+		for _, userId := range []string{"30", "31"} {
+			rr = send(userId, "GET", "/blog/invitations", "")
+
+			var res map[string][]types_blog.BlogInvitation
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res["result"]) != 1 {
+				t.Fatalf("Expected 1 pending invitation, received %v", res["result"])
+			}
+
+			rr = send(userId, "POST", "/blog/invitations/"+res["result"][0].Id+"/accept", "")
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+		}
+
+		b, err := blogStore.GetBlogById("2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(b.Authors) != 3 || blogRole(b, "30") != types_blog.BlogAuthorRoleCoAuthor {
+			t.Fatalf("Expected the collaborators to be listed, received %v", b.Authors)
+		}
+
+		rr = send("30", "PATCH", "/blog/2", `{"description":"Edited together"}`)
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		rr = send("31", "PATCH", "/blog/2", `{"description":"Edited by a reviewer"}`)
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+	t.Run("should compute the reading stats of markdown", func(t *testing.T) {
+		content := strings.Join([]string{
+			"---",
+			"title: Ignored front matter words",
+			"---",
+			"# Intro",
+			"",
+			"Some words to read <b>here</b>.",
+			"",
+			"![diagram](diagram.png)",
+			"",
+			"## Code",
+			"",
+			"```go",
+			"fmt.Println(\"not prose\")",
+			"```",
+		}, "\n")
+
+		stats := readingStats(content)
+
+		expected := types_blog.ReadingStats{
+			WordCount:      7,
+			ReadingTime:    1,
+			HeadingCount:   2,
+			ImageCount:     1,
+			CodeBlockCount: 1,
+		}
+
+		if stats != expected {
+			t.Errorf("Expected %v, received %v", expected, stats)
+		}
+	})
+
+	t.Run("should estimate the reading stats of very long markdown", func(t *testing.T) {
+		section := "## Part\n\nTen words of prose that keep on going and going.\n\n" +
+			"```\ncode that is not read\n```\n\n"
+		parts := maxRenderedStatsSize/len(section) + 1
+		content := strings.Repeat(section, parts)
+
+		stats := readingStats(content)
+
+		if stats.HeadingCount != parts || stats.CodeBlockCount != parts {
+			t.Errorf("Expected %d headings and code blocks, received %v", parts, stats)
+		}
+
+		if stats.WordCount != parts*11 {
+			t.Errorf("Expected %d words, received %d", parts*11, stats.WordCount)
+		}
+	})
+
+	t.Run("should backfill the reading stats of existing blogs", func(t *testing.T) {
+		updated, skipped, err := BackfillReadingStats(&blogStore, mdFileUploadDir, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if updated == 0 || updated+len(skipped) != len(blogStore.DefaultBlogs) {
+			t.Fatalf("Expected every blog to be updated or skipped, received %d", updated)
+		}
+
+		b, err := blogStore.GetBlogById("5")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Stats.WordCount != 1 || b.Stats.HeadingCount != 1 || b.Stats.ReadingTime != 1 {
+			t.Errorf("Unexpected reading stats %v", b.Stats)
+		}
+	})
+	t.Run("should import a jekyll site with a dry run first", func(t *testing.T) {
+		site := t.TempDir()
+		uploads := t.TempDir()
+
+		picture, err := os.ReadFile(filepath.Join(imageUploadDir, "test.jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files := map[string]string{
+			"assets/pic.jpg": string(picture),
+			"_posts/2020-05-01-hello-world.md": strings.Join([]string{
+				"---",
+				"title: Hello World",
+				"date: 2020-05-01 10:00:00 +0000",
+				"tags: [go, imports]",
+				"---",
+				"Some text.",
+				"",
+				"![pic]({{ site.baseurl }}/assets/pic.jpg)",
+				"",
+				"{% highlight go %}",
+			}, "\n"),
+			"_posts/2020-06-01-blog2.md":  "---\ntitle: Taken\n---\nThe slug exists.\n",
+			"_drafts/work-in-progress.md": "---\ntitle: WIP\ncover: /assets/pic.jpg\n---\nSoon.\n",
+		}
+
+		for name, content := range files {
+			path := filepath.Join(site, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		mds := filepath.Join(uploads, "mds")
+		images := filepath.Join(uploads, "images")
+		count := len(blogStore.DefaultBlogs)
+
+		report, err := NewImporter(&blogStore, mds, images, "99", "", true).
+			ImportDirectory(site, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Format != types_blog.ImportFormatJekyll || report.Imported != 2 ||
+			report.Skipped != 1 || report.Failed != 0 {
+			t.Fatalf("Unexpected dry run report %+v", report)
+		}
+
+		if len(blogStore.DefaultBlogs) != count || isDir(mds) || isDir(images) {
+			t.Fatal("Expected the dry run to leave the blogs and uploads untouched")
+		}
+
+		report, err = NewImporter(&blogStore, mds, images, "99", "", false).
+			ImportDirectory(site, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Imported != 2 || len(blogStore.DefaultBlogs) != count+2 {
+			t.Fatalf("Unexpected import report %+v", report)
+		}
+
+		for _, p := range report.Posts {
+			if p.Slug == "hello-world" && len(p.Warnings) != 1 {
+				t.Errorf("Expected a warning for the liquid tag, received %v", p.Warnings)
+			}
+		}
+
+		copied, err := os.ReadDir(images)
+		if err != nil || len(copied) != 1 {
+			t.Fatalf("Expected the shared image to be copied once, received %v", copied)
+		}
+
+		b, err := blogStore.GetBlogBySlug("hello-world")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		date := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+		if b.Status != types_blog.BlogStatusPublished || !b.PublishedAt.Equal(date) ||
+			!b.CreatedAt.Equal(date) || b.PictureName != copied[0].Name() || len(b.Tags) != 2 {
+			t.Errorf("Unexpected imported blog %+v", b)
+		}
+
+		content := blogStore.Contents[b.Id]
+		if !strings.Contains(content, blogImageURL(copied[0].Name())) {
+			t.Errorf("Expected the image reference to be rewritten, received %q", content)
+		}
+
+		draft, err := blogStore.GetBlogBySlug("work-in-progress")
+		if err != nil || draft.Status != types_blog.BlogStatusDraft {
+			t.Errorf("Expected the draft to be imported as a draft, received %+v", draft)
+		}
+	})
+
+	t.Run("should keep the inline images of imported posts", func(t *testing.T) {
+		site := t.TempDir()
+		uploads := t.TempDir()
+
+		picture, err := os.ReadFile(filepath.Join(imageUploadDir, "test.jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files := map[string]string{
+			"assets/cover.jpg":  string(picture),
+			"assets/inline.jpg": string(picture),
+			"_posts/2021-01-01-inline-images.md": "---\ntitle: Inline Images\n" +
+				"cover: /assets/cover.jpg\n---\n![inline](/assets/inline.jpg)\n",
+		}
+
+		for name, content := range files {
+			path := filepath.Join(site, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		mds := filepath.Join(uploads, "mds")
+		images := filepath.Join(uploads, "images")
+
+		report, err := NewImporter(&blogStore, mds, images, "99", "", false).
+			ImportDirectory(site, time.Now())
+		if err != nil || report.Imported != 1 {
+			t.Fatalf("Unexpected import report %+v: %v", report, err)
+		}
+
+		vaultReport, err := NewVault(&blogStore, mds, images, 0, false).Check(time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if vaultReport.RemovedFiles != 0 {
+			t.Errorf("Expected the imported images to be kept, removed %+v", vaultReport.OrphanFiles)
+		}
+
+		copied, err := os.ReadDir(images)
+		if err != nil || len(copied) != 2 {
+			t.Errorf("Expected the cover and the inline image to remain, received %v", copied)
+		}
+	})
+
+	t.Run("should import a wordpress export as admin", func(t *testing.T) {
+		export := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<item>
+		<title>From WordPress</title>
+		<content:encoded><![CDATA[Hello <strong>there</strong>
+
+<img src="https://old.example.com/wp-content/uploads/2019/01/a.jpg" alt="a">]]></content:encoded>
+		<excerpt:encoded><![CDATA[A short excerpt]]></excerpt:encoded>
+		<wp:post_id>7</wp:post_id>
+		<wp:post_date_gmt>2019-01-02 03:04:05</wp:post_date_gmt>
+		<wp:post_name>from-wordpress</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>post</wp:post_type>
+		<category domain="category" nicename="news"><![CDATA[News]]></category>
+		<category domain="post_tag" nicename="wp"><![CDATA[WP]]></category>
+	</item>
+	<item>
+		<title>About</title>
+		<wp:post_name>about</wp:post_name>
+		<wp:status>publish</wp:status>
+		<wp:post_type>page</wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+		picture, err := os.ReadFile(filepath.Join(imageUploadDir, "test.jpg"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uploads := t.TempDir()
+		importHandler := NewHandler(
+			&blogStore,
+			&userStore,
+			filepath.Join(uploads, "mds"),
+			filepath.Join(uploads, "images"),
+			viewCounter,
+		)
+
+		send := func(userId string) *httptest.ResponseRecorder {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+
+			files := map[string][]string{
+				"file":  {"export.xml", "text/xml", export},
+				"cover": {"cover.jpg", "image/jpeg", string(picture)},
+			}
+
+			for field, file := range files {
+				header := textproto.MIMEHeader{}
+				header.Set(
+					"Content-Disposition",
+					fmt.Sprintf(`form-data; name="%s"; filename="%s"`, field, file[0]),
+				)
+				header.Set("Content-Type", file[1])
+
+				part, err := writer.CreatePart(header)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				part.Write([]byte(file[2]))
+			}
+
+			writer.Close()
+
+			req, err := http.NewRequest("POST", "/blog/import", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/blog/import", importHandler.importBlogs).Methods("POST")
+
+			router.ServeHTTP(rr, req)
+
+			return rr
+		}
+
+		if rr := send("10"); rr.Code != http.StatusForbidden {
+			t.Fatalf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+
+		rr := send("99")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d: %s", http.StatusOK, rr.Code, rr.Body)
+		}
+
+		var report types_blog.ImportReport
+		if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Format != types_blog.ImportFormatWordPress || report.Imported != 1 ||
+			len(report.Posts) != 1 {
+			t.Fatalf("Unexpected import report %+v", report)
+		}
+
+		b, err := blogStore.GetBlogBySlug("from-wordpress")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		date := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+		if b.Description != "A short excerpt" || !b.PublishedAt.Equal(date) ||
+			len(b.Tags) != 1 || b.Tags[0].Name != "WP" || b.AuthorId != "99" {
+			t.Errorf("Unexpected imported blog %+v", b)
+		}
+
+		expected := "Hello **there**\n\n" +
+			"![a](https://old.example.com/wp-content/uploads/2019/01/a.jpg)\n"
+		if content := blogStore.Contents[b.Id]; content != expected {
+			t.Errorf("Expected content %q, received %q", expected, content)
+		}
+	})
+}
+
+type MockBlogStore struct {
+	DefaultBlogs []types_blog.Blog
+	Follows      map[string][]string
+	Revisions    map[string][]types_blog.BlogRevision
+	Categories   []types_blog.Category
+	Reactions    map[string]map[string]bool
+	Views        map[types_blog.BlogView]bool
+	Contents     map[string]string
+	SlugHistory  map[string]string
+	RelatedCalls int
+	Series       []types_blog.Series
+	SeriesParts  map[string][]string
+	Invitations  []types_blog.BlogInvitation
+}
+
+type MockGetBlogsResult struct {
+	Result map[string][]types_blog.Blog
+}
+
+type MockUserStore struct {
+	DefaultUsers []types_user.User
+}
+
+func (m *MockUserStore) GetUserById(id string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Id == id {
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByUsername(username string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByEmail(email string) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByUsernameOrEmail(
+	username string,
+	email string,
+) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) CreateUser(u types_user.RegisterUserPayload) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUsers(
+	query types_user.SearchUserQuery,
+) ([]types_user.User, int, error) {
+	return nil, 0, nil
+}
+
+func (m *MockUserStore) DeleteUserById(
+	id string,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) DeleteUserByUsername(
+	username string,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) CreateEmailChange(
+	change types_user.CreateEmailChangePayload,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetEmailChangeByTokenHash(
+	tokenHash string,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetEmailChangeByUndoTokenHash(
+	undoTokenHash string,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) ConfirmEmailChange(id string) error {
+	return nil
+}
+
+func (m *MockUserStore) RevertEmailChange(id string) error {
+	return nil
+}
+
+func (m *MockUserStore) ChangeUsername(id string, username string) error {
+	return nil
+}
+
+func (m *MockUserStore) GetUsernameHistory(
+	userId string,
+) ([]types_user.UsernameHistory, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetLatestUsernameHistoryByUsername(
+	username string,
+) (*types_user.UsernameHistory, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetPreferences(userId string) (*types_user.Preferences, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) UpdatePreferences(
+	userId string,
+	preferences types_user.Preferences,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) FollowUser(followerId string, followeeId string) error {
+	return nil
+}
+
+func (m *MockUserStore) UnfollowUser(followerId string, followeeId string) error {
+	return nil
+}
+
+func (m *MockUserStore) GetFollowCounts(userId string) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (m *MockBlogStore) GetBlogById(id string) (*types_blog.Blog, error) {
+	for i := range m.DefaultBlogs {
+		b := m.DefaultBlogs[i]
+
+		if b.Id == id {
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find blog")
+}
+
+func (m *MockBlogStore) GetBlogBySlug(slug string) (*types_blog.Blog, error) {
+	for i := range m.DefaultBlogs {
+		b := m.DefaultBlogs[i]
+
+		if b.Slug == slug {
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find blog")
+}
+
+func (m *MockBlogStore) CreateBlog(b types_blog.CreateBlogPayload) (*types_blog.Blog, error) {
+	created := types_blog.Blog{
+		Id:          strconv.Itoa(rand.Int()),
+		Title:       b.Title,
+		Description: b.Description,
+		Slug:        b.Slug,
+		PictureName: b.PictureName,
+		MDFilename:  b.MDFilename,
+		Visibility:  b.Visibility,
+		Status:      b.Status,
+		PublishedAt: b.PublishedAt,
+		ScheduledAt: b.ScheduledAt,
+		ExpiresAt:   b.ExpiresAt,
+		AuthorId:    b.AuthorId,
+		CategoryId:  b.CategoryId,
+		Tags:        mockTags(b.Tags),
+		Stats:       b.Stats,
+		CreatedAt:   time.Now(),
+	}
+
+	if b.CreatedAt != nil {
+		created.CreatedAt = *b.CreatedAt
+	}
+
+	if b.AuthorId != "" {
+		created.Authors = []types_blog.BlogAuthor{
+			{UserId: b.AuthorId, Role: types_blog.BlogAuthorRoleOwner},
+		}
+	}
+
+	m.DefaultBlogs = append(m.DefaultBlogs, created)
+	m.addRevision(created, b.Content, b.AuthorId)
+	m.setContent(created.Id, b.Content)
+
+	return &created, nil
+}
+
+func (m *MockBlogStore) GetBlogs(
+	query types_blog.SearchBlogQuery,
+) ([]types_blog.Blog, int, error) {
+	var res []types_blog.Blog
+
+	for i := range m.DefaultBlogs {
+		b := m.DefaultBlogs[i]
+
+		if query.PublicOnly && b.Visibility == types_blog.BlogVisibilityMembers {
+			continue
+		}
+
+		if query.Status != "" && b.Status != query.Status {
+			continue
+		}
+
+		if query.AuthorId != "" && blogRole(&b, query.AuthorId) == "" {
+			continue
+		}
+
+		if query.Tag != "" && !slices.ContainsFunc(b.Tags, func(t types_blog.Tag) bool {
+			return t.Slug == query.Tag
+		}) {
+			continue
+		}
+
+		if query.Category != "" && !m.inCategory(b.CategoryId, query.Category) {
+			continue
+		}
+
+		if len(query.Keyword) > 0 {
+			if strings.Contains(strings.ToLower(b.Description), query.Keyword) ||
+				strings.Contains(strings.ToLower(b.Title), query.Keyword) {
+				res = append(res, b)
+			}
+		} else {
 			res = append(res, b)
 		}
 	}
 
+	if query.SortBy == "publishedAt" {
+		published := func(b types_blog.Blog) time.Time {
+			if b.PublishedAt != nil {
+				return *b.PublishedAt
+			}
+			return b.CreatedAt
+		}
+
+		sort.SliceStable(res, func(i, j int) bool {
+			return published(res[i]).After(published(res[j]))
+		})
+	}
+
+	total := len(res)
+	offset := (query.Page - 1) * query.Limit
+
+	if offset >= len(res) {
+		return []types_blog.Blog{}, total, nil
+	}
+
+	res = res[offset:]
+	if len(res) > query.Limit {
+		res = res[:query.Limit]
+	}
+
+	return res, total, nil
+}
+
+func (m *MockBlogStore) UpdateBlog(id string, payload types_blog.UpdateBlogPayload) error {
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+
+		if id == b.Id {
+			if b.Slug != payload.Slug {
+				if m.SlugHistory == nil {
+					m.SlugHistory = map[string]string{}
+				}
+
+				m.SlugHistory[b.Slug] = b.Id
+				if m.SlugHistory[payload.Slug] == b.Id {
+					delete(m.SlugHistory, payload.Slug)
+				}
+			}
+
+			b.Title = payload.Title
+			b.Description = payload.Description
+			b.Slug = payload.Slug
+			b.PictureName = payload.PictureName
+			b.MDFilename = payload.MDFilename
+			b.Visibility = payload.Visibility
+			b.CategoryId = payload.CategoryId
+			b.UpdatedAt = payload.UpdatedAt
+			b.Stats = payload.Stats
+
+			if payload.Tags != nil {
+				b.Tags = mockTags(payload.Tags)
+			}
+
+			if payload.Content != nil {
+				m.addRevision(*b, *payload.Content, payload.EditorId)
+				m.setContent(id, *payload.Content)
+			} else {
+				m.addRevision(*b, "", payload.EditorId)
+				delete(m.Contents, id)
+			}
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Blog not found to update")
+}
+
+func (m *MockBlogStore) DeleteBlogById(
+	id string,
+) error {
+	var res []types_blog.Blog
+
+	for i := range m.DefaultBlogs {
+		u := m.DefaultBlogs[i]
+
+		if u.Id != id {
+			res = append(res, u)
+		}
+	}
+
+	if len(res) == len(m.DefaultBlogs) {
+		return fmt.Errorf("Blog not found to delete")
+	}
+
+	m.DefaultBlogs = res
+
+	for seriesId := range m.SeriesParts {
+		m.RemoveSeriesPart(seriesId, id)
+	}
+
+	return nil
+}
+
+func (m *MockBlogStore) GetFeed(query types_blog.FeedQuery) ([]types_blog.Blog, error) {
+	res := []types_blog.Blog{}
+
+	for i := len(m.DefaultBlogs) - 1; i >= 0; i-- {
+		b := m.DefaultBlogs[i]
+
+		if b.Status != types_blog.BlogStatusPublished {
+			continue
+		}
+
+		for _, followeeId := range m.Follows[query.UserId] {
+			switch blogRole(&b, followeeId) {
+			case types_blog.BlogAuthorRoleOwner, types_blog.BlogAuthorRoleCoAuthor:
+				if len(res) < query.Limit {
+					res = append(res, b)
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (m *MockBlogStore) UpdateBlogStatus(
+	id string,
+	status types_blog.UpdateBlogStatusPayload,
+) error {
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+
+		if id == b.Id {
+			b.Status = status.Status
+			b.PublishedAt = status.PublishedAt
+			b.ScheduledAt = status.ScheduledAt
+			b.ExpiresAt = status.ExpiresAt
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Blog not found to update")
+}
+
+func (m *MockBlogStore) PublishScheduledBlogs(now time.Time) (int64, error) {
+	var count int64
+
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+
+		if b.Status == types_blog.BlogStatusScheduled && !b.ScheduledAt.After(now) {
+			b.Status = types_blog.BlogStatusPublished
+			b.PublishedAt = b.ScheduledAt
+			b.ScheduledAt = nil
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *MockBlogStore) UnpublishExpiredBlogs(now time.Time) (int64, error) {
+	var count int64
+
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+
+		if b.Status == types_blog.BlogStatusPublished && b.ExpiresAt != nil &&
+			!b.ExpiresAt.After(now) {
+			b.Status = types_blog.BlogStatusDraft
+			b.ExpiresAt = nil
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *MockBlogStore) GetBlogRevisions(blogId string) ([]types_blog.BlogRevision, error) {
+	res := []types_blog.BlogRevision{}
+
+	for _, r := range m.Revisions[blogId] {
+		r.Content = ""
+		res = append(res, r)
+	}
+
+	return res, nil
+}
+
+func (m *MockBlogStore) GetBlogRevision(
+	blogId string,
+	revision int,
+) (*types_blog.BlogRevision, error) {
+	for _, r := range m.Revisions[blogId] {
+		if r.Revision == revision {
+			return &r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find revision")
+}
+
+func (m *MockBlogStore) addRevision(b types_blog.Blog, content string, editorId string) {
+	if m.Revisions == nil {
+		m.Revisions = map[string][]types_blog.BlogRevision{}
+	}
+
+	revisions := m.Revisions[b.Id]
+
+	m.Revisions[b.Id] = append(revisions, types_blog.BlogRevision{
+		Id:          strconv.Itoa(rand.Int()),
+		BlogId:      b.Id,
+		Revision:    len(revisions) + 1,
+		Title:       b.Title,
+		Description: b.Description,
+		Slug:        b.Slug,
+		PictureName: b.PictureName,
+		MDFilename:  b.MDFilename,
+		Content:     content,
+		EditorId:    editorId,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func (m *MockBlogStore) GetTags() ([]types_blog.Tag, error) {
+	res := []types_blog.Tag{}
+
+	for _, b := range m.DefaultBlogs {
+		for _, t := range b.Tags {
+			i := slices.IndexFunc(res, func(r types_blog.Tag) bool { return r.Id == t.Id })
+			if i == -1 {
+				res = append(res, t)
+				i = len(res) - 1
+			}
+
+			if b.Status == types_blog.BlogStatusPublished {
+				res[i].PostCount++
+			}
+		}
+	}
+
 	return res, nil
 }
 
-func (m *MockBlogStore) UpdateBlog(id string, payload types_blog.UpdateBlogPayload) error {
-	var res []types_blog.Blog
+func (m *MockBlogStore) GetTagBySlug(slug string) (*types_blog.Tag, error) {
+	tags, _ := m.GetTags()
+
+	for _, t := range tags {
+		if t.Slug == slug {
+			return &t, nil
+		}
+	}
 
-	isUpdated := false
+	return nil, fmt.Errorf("Cannot find tag")
+}
 
+func (m *MockBlogStore) RenameTag(id string, name string, slug string) error {
 	for i := range m.DefaultBlogs {
-		b := m.DefaultBlogs[i]
+		for j := range m.DefaultBlogs[i].Tags {
+			t := &m.DefaultBlogs[i].Tags[j]
 
-		if id == b.Id {
-			updatedBlog := types_blog.Blog{
-				Id:          b.Id,
-				Title:       payload.Title,
-				Description: payload.Description,
-				Slug:        payload.Slug,
-				PictureName: payload.PictureName,
-				MDFilename:  payload.MDFilename,
-				CreatedAt:   b.CreatedAt,
-				UpdatedAt:   payload.UpdatedAt,
+			if t.Id == id {
+				t.Name = name
+				t.Slug = slug
 			}
-
-			res = append(res, updatedBlog)
-			isUpdated = true
 		}
 	}
 
-	if !isUpdated {
-		return fmt.Errorf("Blog not found to update")
+	return nil
+}
+
+func (m *MockBlogStore) MergeTags(sourceId string, targetId string) error {
+	target := types_blog.Tag{}
+	tags, _ := m.GetTags()
+
+	for _, t := range tags {
+		if t.Id == targetId {
+			target = types_blog.Tag{Id: t.Id, Name: t.Name, Slug: t.Slug}
+		}
 	}
 
-	m.DefaultBlogs = res
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+
+		i := slices.IndexFunc(b.Tags, func(t types_blog.Tag) bool { return t.Id == sourceId })
+		if i == -1 {
+			continue
+		}
+
+		b.Tags = slices.Delete(b.Tags, i, i+1)
+
+		if !slices.ContainsFunc(b.Tags, func(t types_blog.Tag) bool { return t.Id == targetId }) {
+			b.Tags = append(b.Tags, target)
+		}
+	}
 
 	return nil
 }
 
-func (m *MockBlogStore) DeleteBlogById(
-	id string,
-) error {
-	var res []types_blog.Blog
+func (m *MockBlogStore) GetCategories() ([]types_blog.Category, error) {
+	return m.Categories, nil
+}
+
+func (m *MockBlogStore) GetCategoryById(id string) (*types_blog.Category, error) {
+	for _, c := range m.Categories {
+		if c.Id == id {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find category")
+}
+
+func (m *MockBlogStore) GetCategoryBySlug(slug string) (*types_blog.Category, error) {
+	for _, c := range m.Categories {
+		if c.Slug == slug {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find category")
+}
+
+func (m *MockBlogStore) CreateCategory(
+	payload types_blog.CreateCategoryPayload,
+) (*types_blog.Category, error) {
+	created := types_blog.Category{
+		Id:        strconv.Itoa(rand.Int()),
+		Name:      payload.Name,
+		Slug:      payload.Slug,
+		ParentId:  payload.ParentId,
+		CreatedAt: time.Now(),
+	}
+
+	m.Categories = append(m.Categories, created)
+
+	return &created, nil
+}
+
+func (m *MockBlogStore) inCategory(categoryId string, slug string) bool {
+	for categoryId != "" {
+		c, err := m.GetCategoryById(categoryId)
+		if err != nil {
+			return false
+		}
+
+		if c.Slug == slug {
+			return true
+		}
+
+		categoryId = c.ParentId
+	}
+
+	return false
+}
+
+func mockTags(names []string) []types_blog.Tag {
+	tags := []types_blog.Tag{}
+
+	for _, name := range names {
+		slug := utils.CreateSlug(name)
+		tags = append(tags, types_blog.Tag{Id: slug, Name: name, Slug: slug})
+	}
+
+	return tags
+}
+
+func (m *MockBlogStore) ToggleReaction(
+	blogId string,
+	userId string,
+	reaction string,
+) (bool, error) {
+	if m.Reactions == nil {
+		m.Reactions = map[string]map[string]bool{}
+	}
+
+	if m.Reactions[blogId] == nil {
+		m.Reactions[blogId] = map[string]bool{}
+	}
+
+	key := fmt.Sprintf("%s:%s", userId, reaction)
+	if m.Reactions[blogId][key] {
+		delete(m.Reactions[blogId], key)
+		return false, nil
+	}
+
+	m.Reactions[blogId][key] = true
+
+	return true, nil
+}
+
+func (m *MockBlogStore) GetReactionCounts(blogId string) (map[string]int, error) {
+	counts := map[string]int{}
+
+	for key := range m.Reactions[blogId] {
+		counts[key[strings.Index(key, ":")+1:]]++
+	}
+
+	return counts, nil
+}
+
+func (m *MockBlogStore) RecordViews(views []types_blog.BlogView) (int64, error) {
+	if m.Views == nil {
+		m.Views = map[types_blog.BlogView]bool{}
+	}
+
+	var counted int64
+
+	for _, v := range views {
+		if m.Views[v] {
+			continue
+		}
+
+		for i := range m.DefaultBlogs {
+			if m.DefaultBlogs[i].Id == v.BlogId {
+				m.DefaultBlogs[i].ViewCount++
+				m.Views[v] = true
+				counted++
+			}
+		}
+	}
+
+	return counted, nil
+}
+
+func (m *MockBlogStore) GetBlogContent(id string) (*string, error) {
+	content, ok := m.Contents[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &content, nil
+}
+
+func (m *MockBlogStore) setContent(id string, content string) {
+	if m.Contents == nil {
+		m.Contents = map[string]string{}
+	}
+
+	m.Contents[id] = content
+}
+
+func (m *MockBlogStore) GetBlogByOldSlug(slug string) (*types_blog.Blog, error) {
+	id, ok := m.SlugHistory[slug]
+	if !ok {
+		return nil, fmt.Errorf("Cannot find blog")
+	}
+
+	return m.GetBlogById(id)
+}
+
+func (m *MockBlogStore) GetBlogFiles() ([]types_blog.BlogFiles, error) {
+	files := []types_blog.BlogFiles{}
+
+	for _, b := range m.DefaultBlogs {
+		files = append(files, types_blog.BlogFiles{
+			BlogId:      b.Id,
+			Slug:        b.Slug,
+			MDFilename:  b.MDFilename,
+			PictureName: b.PictureName,
+		})
+	}
+
+	return files, nil
+}
+
+func (m *MockBlogStore) GetContentImageNames() ([]string, error) {
+	contents := []string{}
+
+	for _, content := range m.Contents {
+		contents = append(contents, content)
+	}
+
+	for _, revisions := range m.Revisions {
+		for _, r := range revisions {
+			contents = append(contents, r.Content)
+		}
+	}
+
+	names := []string{}
+	for _, content := range contents {
+		for _, match := range contentImageRegex.FindAllStringSubmatch(content, -1) {
+			names = append(names, match[1])
+		}
+	}
+
+	return names, nil
+}
+
+func (m *MockBlogStore) GetRevisionFiles() ([]types_blog.BlogFiles, error) {
+	files := []types_blog.BlogFiles{}
+
+	for _, revisions := range m.Revisions {
+		for _, r := range revisions {
+			files = append(files, types_blog.BlogFiles{
+				BlogId:      r.BlogId,
+				Slug:        r.Slug,
+				MDFilename:  r.MDFilename,
+				PictureName: r.PictureName,
+			})
+		}
+	}
+
+	return files, nil
+}
+
+func (m *MockBlogStore) sitemapBlogs() []types_blog.Blog {
+	blogs := []types_blog.Blog{}
+
+	for _, b := range m.DefaultBlogs {
+		if b.Status == types_blog.BlogStatusPublished &&
+			b.Visibility != types_blog.BlogVisibilityMembers {
+			blogs = append(blogs, b)
+		}
+	}
+
+	return blogs
+}
+
+func (m *MockBlogStore) CountSitemapEntries() (int, error) {
+	return len(m.sitemapBlogs()), nil
+}
+
+func (m *MockBlogStore) GetSitemapEntries(
+	offset int,
+	limit int,
+) ([]types_blog.SitemapEntry, error) {
+	entries := []types_blog.SitemapEntry{}
+
+	for i, b := range m.sitemapBlogs() {
+		if i >= offset && i < offset+limit {
+			entries = append(entries, types_blog.SitemapEntry{Slug: b.Slug, UpdatedAt: b.UpdatedAt})
+		}
+	}
+
+	return entries, nil
+}
+
+func (m *MockBlogStore) GetRelatedBlogs(blogId string, limit int) ([]types_blog.Blog, error) {
+	m.RelatedCalls++
 
+	var source *types_blog.Blog
 	for i := range m.DefaultBlogs {
-		u := m.DefaultBlogs[i]
+		if m.DefaultBlogs[i].Id == blogId {
+			source = &m.DefaultBlogs[i]
+		}
+	}
 
-		if u.Id != id {
-			res = append(res, u)
+	if source == nil {
+		return nil, nil
+	}
+
+	tags := map[string]bool{}
+	for _, t := range source.Tags {
+		tags[t.Id] = true
+	}
+
+	related := []types_blog.Blog{}
+	for _, b := range m.sitemapBlogs() {
+		if b.Id == blogId {
+			continue
+		}
+
+		for _, t := range b.Tags {
+			if tags[t.Id] {
+				b.Rank++
+			}
 		}
+
+		related = append(related, b)
 	}
 
-	if len(res) == len(m.DefaultBlogs) {
-		return fmt.Errorf("Blog not found to delete")
+	sort.SliceStable(related, func(i, j int) bool {
+		return related[i].Rank > related[j].Rank
+	})
+
+	if len(related) > limit {
+		related = related[:limit]
 	}
 
-	m.DefaultBlogs = res
+	return related, nil
+}
+
+func (m *MockBlogStore) GetSeries() ([]types_blog.Series, error) {
+	series := []types_blog.Series{}
+
+	for _, s := range m.Series {
+		s.PartCount = len(m.SeriesParts[s.Id])
+		series = append(series, s)
+	}
+
+	return series, nil
+}
+
+func (m *MockBlogStore) GetSeriesById(id string) (*types_blog.Series, error) {
+	series, _ := m.GetSeries()
+
+	for i := range series {
+		if series[i].Id == id {
+			return &series[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Series not found")
+}
+
+func (m *MockBlogStore) GetSeriesBySlug(slug string) (*types_blog.Series, error) {
+	for _, s := range m.Series {
+		if s.Slug == slug {
+			return m.GetSeriesById(s.Id)
+		}
+	}
+
+	return nil, fmt.Errorf("Series not found")
+}
+
+func (m *MockBlogStore) GetSeriesByBlogId(blogId string) (*types_blog.Series, error) {
+	for id, parts := range m.SeriesParts {
+		if slices.Contains(parts, blogId) {
+			return m.GetSeriesById(id)
+		}
+	}
+
+	return nil, fmt.Errorf("Series not found")
+}
+
+func (m *MockBlogStore) GetSeriesParts(seriesId string) ([]types_blog.Blog, error) {
+	parts := []types_blog.Blog{}
+
+	for _, id := range m.SeriesParts[seriesId] {
+		if b, err := m.GetBlogById(id); err == nil {
+			parts = append(parts, *b)
+		}
+	}
+
+	return parts, nil
+}
+
+func (m *MockBlogStore) CreateSeries(
+	series types_blog.CreateSeriesPayload,
+) (*types_blog.Series, error) {
+	s := types_blog.Series{
+		Id:          strconv.Itoa(len(m.Series) + 1),
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		AuthorId:    series.AuthorId,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	m.Series = append(m.Series, s)
+
+	return &s, nil
+}
+
+func (m *MockBlogStore) UpdateSeries(id string, series types_blog.UpdateSeriesPayload) error {
+	for i := range m.Series {
+		if m.Series[i].Id == id {
+			m.Series[i].Title = series.Title
+			m.Series[i].Slug = series.Slug
+			m.Series[i].Description = series.Description
+			m.Series[i].UpdatedAt = time.Now()
+		}
+	}
+
+	return nil
+}
+
+func (m *MockBlogStore) DeleteSeries(id string) error {
+	m.Series = slices.DeleteFunc(m.Series, func(s types_blog.Series) bool { return s.Id == id })
+	delete(m.SeriesParts, id)
+
+	return nil
+}
+
+func (m *MockBlogStore) AddSeriesPart(seriesId string, blogId string, position int) error {
+	if m.SeriesParts == nil {
+		m.SeriesParts = map[string][]string{}
+	}
+
+	parts := m.SeriesParts[seriesId]
+	if position < 1 || position > len(parts) {
+		position = len(parts) + 1
+	}
+
+	m.SeriesParts[seriesId] = slices.Insert(parts, position-1, blogId)
+
+	return nil
+}
+
+func (m *MockBlogStore) RemoveSeriesPart(seriesId string, blogId string) error {
+	m.SeriesParts[seriesId] = slices.DeleteFunc(
+		m.SeriesParts[seriesId],
+		func(id string) bool { return id == blogId },
+	)
+
+	return nil
+}
+
+func (m *MockBlogStore) ReorderSeries(seriesId string, blogIds []string) error {
+	m.SeriesParts[seriesId] = slices.Clone(blogIds)
+	return nil
+}
+
+func (m *MockBlogStore) CreateBlogInvitation(
+	invitation types_blog.InviteBlogAuthorPayload,
+) (*types_blog.BlogInvitation, error) {
+	created := types_blog.BlogInvitation{
+		Id:        strconv.Itoa(len(m.Invitations) + 1),
+		BlogId:    invitation.BlogId,
+		InviterId: invitation.InviterId,
+		InviteeId: invitation.InviteeId,
+		Role:      invitation.Role,
+		Status:    types_blog.BlogInvitationStatusPending,
+		CreatedAt: time.Now(),
+	}
+
+	m.Invitations = append(m.Invitations, created)
+
+	return &created, nil
+}
+
+func (m *MockBlogStore) GetBlogInvitationById(id string) (*types_blog.BlogInvitation, error) {
+	for i := range m.Invitations {
+		if m.Invitations[i].Id == id {
+			invitation := m.Invitations[i]
+			return &invitation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Invitation not found")
+}
+
+func (m *MockBlogStore) GetPendingBlogInvitations(
+	userId string,
+) ([]types_blog.BlogInvitation, error) {
+	invitations := []types_blog.BlogInvitation{}
+
+	for _, i := range m.Invitations {
+		if i.InviteeId == userId && i.Status == types_blog.BlogInvitationStatusPending {
+			invitations = append(invitations, i)
+		}
+	}
+
+	return invitations, nil
+}
+
+func (m *MockBlogStore) RespondToBlogInvitation(id string, accepted bool) error {
+	for i := range m.Invitations {
+		invitation := &m.Invitations[i]
+		if invitation.Id != id {
+			continue
+		}
+
+		now := time.Now()
+		invitation.RespondedAt = &now
+		invitation.Status = types_blog.BlogInvitationStatusDeclined
+
+		if !accepted {
+			return nil
+		}
+
+		invitation.Status = types_blog.BlogInvitationStatusAccepted
+
+		for j := range m.DefaultBlogs {
+			b := &m.DefaultBlogs[j]
+			if b.Id == invitation.BlogId {
+				b.Authors = append(b.Authors, types_blog.BlogAuthor{
+					UserId: invitation.InviteeId,
+					Role:   invitation.Role,
+				})
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("Invitation not found")
+}
+
+func (m *MockBlogStore) RemoveBlogAuthor(blogId string, userId string) error {
+	for i := range m.DefaultBlogs {
+		b := &m.DefaultBlogs[i]
+		if b.Id == blogId {
+			b.Authors = slices.DeleteFunc(b.Authors, func(a types_blog.BlogAuthor) bool {
+				return a.UserId == userId && a.Role != types_blog.BlogAuthorRoleOwner
+			})
+		}
+	}
+
+	return nil
+}
+
+func (m *MockBlogStore) UpdateBlogStats(id string, stats types_blog.ReadingStats) error {
+	for i := range m.DefaultBlogs {
+		if m.DefaultBlogs[i].Id == id {
+			m.DefaultBlogs[i].Stats = stats
+		}
+	}
 
 	return nil
 }
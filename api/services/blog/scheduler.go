@@ -0,0 +1,63 @@
+package blog
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+)
+
+type Scheduler struct {
+	store    types_blog.BlogStore
+	related  *relatedCache
+	interval time.Duration
+}
+
+// NewScheduler shares the related cache of the handler, so the blogs it
+// publishes or takes down show up in related lists right away.
+func (h *Handler) NewScheduler(interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:    h.store,
+		related:  h.related,
+		interval: interval,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.tick()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+func (s *Scheduler) tick() {
+	now := time.Now()
+
+	published, err := s.store.PublishScheduledBlogs(now)
+	if err != nil {
+		log.Printf("failed to publish scheduled blogs: %v", err)
+	} else if published > 0 {
+		log.Printf("published %d scheduled blogs", published)
+	}
+
+	unpublished, err := s.store.UnpublishExpiredBlogs(now)
+	if err != nil {
+		log.Printf("failed to unpublish expired blogs: %v", err)
+	} else if unpublished > 0 {
+		log.Printf("unpublished %d expired blogs", unpublished)
+	}
+
+	if published > 0 || unpublished > 0 {
+		s.related.invalidate()
+	}
+}
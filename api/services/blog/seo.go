@@ -0,0 +1,205 @@
+package blog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+// sitemapPageSize is the most URLs a single sitemap may list. Larger vaults
+// are split into pages referenced from a sitemap index.
+var sitemapPageSize = 50000
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc string `xml:"loc"`
+}
+
+// RegisterSiteRoutes mounts the crawler files, which have to live at the root
+// of the host rather than under the API prefix.
+func (h *Handler) RegisterSiteRoutes(router *mux.Router) {
+	router.HandleFunc("/robots.txt", h.getRobots).Methods("GET")
+	router.HandleFunc("/sitemap.xml", h.getSitemap).Methods("GET")
+	router.HandleFunc("/sitemap-{page:[0-9]+}.xml", h.getSitemap).Methods("GET")
+}
+
+func (h *Handler) getRobots(w http.ResponseWriter, r *http.Request) {
+	robots := strings.Join([]string{
+		"User-agent: *",
+		"Allow: /",
+		"Disallow: /api/",
+		"Allow: /api/v1/blog/image/",
+		"",
+		fmt.Sprintf("Sitemap: %s/sitemap.xml", config.Env.Host),
+		"",
+	}, "\n")
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(robots))
+}
+
+func (h *Handler) getSitemap(w http.ResponseWriter, r *http.Request) {
+	total, err := h.store.CountSitemapEntries()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	// The home page is the first url of the sitemap, so it takes one of the
+	// slots of the first page.
+	pages := (total + 1 + sitemapPageSize - 1) / sitemapPageSize
+
+	page := 1
+	if p, ok := mux.Vars(r)["page"]; ok {
+		page, err = strconv.Atoi(p)
+		if err != nil || page < 1 || page > pages {
+			utils.WriteErrorInResponse(w, http.StatusNotFound, "Sitemap not found")
+			return
+		}
+	} else if pages > 1 {
+		index := sitemapIndex{}
+		for i := 1; i <= pages; i++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapPointer{
+				Loc: fmt.Sprintf("%s/sitemap-%d.xml", config.Env.Host, i),
+			})
+		}
+
+		writeXMLFeed(w, "application/xml; charset=utf-8", index)
+		return
+	}
+
+	set := sitemapURLSet{URLs: []sitemapURL{}}
+	offset, limit := (page-1)*sitemapPageSize-1, sitemapPageSize
+
+	if page == 1 {
+		set.URLs = append(set.URLs, sitemapURL{Loc: fmt.Sprintf("%s/", config.Env.Host)})
+		offset, limit = 0, sitemapPageSize-1
+	}
+
+	entries := []types_blog.SitemapEntry{}
+	if limit > 0 {
+		entries, err = h.store.GetSitemapEntries(offset, limit)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+			return
+		}
+	}
+
+	for _, e := range entries {
+		u := sitemapURL{Loc: blogURL(e.Slug)}
+		if !e.UpdatedAt.IsZero() {
+			u.LastMod = e.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+
+		set.URLs = append(set.URLs, u)
+	}
+
+	writeXMLFeed(w, "application/xml; charset=utf-8", set)
+}
+
+func (h *Handler) getMeta(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	b, err := h.store.GetBlogBySlug(slug)
+	if err != nil || b == nil {
+		if h.redirectOldSlug(w, r, slug) {
+			return
+		}
+
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !h.canRead(w, r, b) {
+		return
+	}
+
+	meta := types_blog.BlogMeta{
+		Title:         b.Title,
+		Description:   b.Description,
+		URL:           blogURL(b.Slug),
+		Type:          "article",
+		SiteName:      siteName,
+		Tags:          []string{},
+		PublishedTime: b.PublishedAt,
+		ModifiedTime:  b.UpdatedAt,
+	}
+
+	if b.PictureName != "" {
+		meta.Image = blogImageURL(b.PictureName)
+	}
+
+	if u, err := h.userStore.GetUserById(b.AuthorId); err == nil && u != nil {
+		meta.Author = strings.TrimSpace(fmt.Sprintf("%s %s", u.FirstName, u.LastName))
+		if meta.Author == "" {
+			meta.Author = u.Username
+		}
+	}
+
+	for _, t := range b.Tags {
+		meta.Tags = append(meta.Tags, t.Name)
+	}
+
+	meta.OpenGraph = map[string]string{
+		"og:title":       meta.Title,
+		"og:description": meta.Description,
+		"og:url":         meta.URL,
+		"og:type":        meta.Type,
+		"og:site_name":   meta.SiteName,
+	}
+
+	meta.Twitter = map[string]string{
+		"twitter:card":        "summary",
+		"twitter:title":       meta.Title,
+		"twitter:description": meta.Description,
+	}
+
+	if meta.Image != "" {
+		meta.OpenGraph["og:image"] = meta.Image
+		meta.OpenGraph["og:image:alt"] = meta.Title
+		meta.Twitter["twitter:card"] = "summary_large_image"
+		meta.Twitter["twitter:image"] = meta.Image
+	}
+
+	if meta.PublishedTime != nil {
+		meta.OpenGraph["article:published_time"] = meta.PublishedTime.UTC().Format(time.RFC3339)
+	}
+
+	if !meta.ModifiedTime.IsZero() {
+		meta.OpenGraph["article:modified_time"] = meta.ModifiedTime.UTC().Format(time.RFC3339)
+	}
+
+	if meta.Author != "" {
+		meta.OpenGraph["article:author"] = meta.Author
+	}
+
+	if len(meta.Tags) > 0 {
+		meta.OpenGraph["article:tag"] = strings.Join(meta.Tags, ",")
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, meta, nil)
+}
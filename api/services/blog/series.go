@@ -0,0 +1,468 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+func (h *Handler) getSeriesList(w http.ResponseWriter, r *http.Request) {
+	series, err := h.store.GetSeries()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_blog.Series{
+		"result": series,
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) getSeries(w http.ResponseWriter, r *http.Request) {
+	s, err := h.store.GetSeriesBySlug(mux.Vars(r)["slug"])
+	if err != nil || s == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	h.writeSeries(w, r, http.StatusOK, s)
+}
+
+func (h *Handler) createSeries(w http.ResponseWriter, r *http.Request) {
+	var payload types_blog.CreateSeriesPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	payload.Slug = utils.CreateSlug(payload.Title)
+	if payload.Slug == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series title")
+		return
+	}
+
+	if s, _ := h.store.GetSeriesBySlug(payload.Slug); s != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another series with that title already exists",
+		)
+		return
+	}
+
+	payload.AuthorId, _ = r.Context().Value("userId").(string)
+
+	s, err := h.store.CreateSeries(payload)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusCreated, s, nil)
+}
+
+func (h *Handler) updateSeries(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types_blog.UpdateSeriesPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	if payload.Title == "" {
+		payload.Title = s.Title
+	}
+
+	if payload.Description == "" {
+		payload.Description = s.Description
+	}
+
+	payload.Slug = utils.CreateSlug(payload.Title)
+	if payload.Slug == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series title")
+		return
+	}
+
+	if other, _ := h.store.GetSeriesBySlug(payload.Slug); other != nil && other.Id != s.Id {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another series with that title already exists",
+		)
+		return
+	}
+
+	if err := h.store.UpdateSeries(s.Id, payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeriesById(w, r, s.Id)
+}
+
+func (h *Handler) deleteSeries(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.DeleteSeries(s.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Series with id %s has been deleted successfully", s.Id),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) addSeriesPart(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types_blog.AddSeriesPartPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series part payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	b, err := h.store.GetBlogById(payload.BlogId)
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Blog doesn't exist")
+		return
+	}
+
+	switch blogRole(b, s.AuthorId) {
+	case types_blog.BlogAuthorRoleOwner, types_blog.BlogAuthorRoleCoAuthor:
+	default:
+		if !auth.IsAdmin(r, h.userStore) {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusForbidden,
+				"Only blogs the series author owns or co-authors can be added to it",
+			)
+			return
+		}
+	}
+
+	if current, _ := h.store.GetSeriesByBlogId(b.Id); current != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "The blog is already part of a series")
+		return
+	}
+
+	if err := h.store.AddSeriesPart(s.Id, b.Id, payload.Position); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeriesById(w, r, s.Id)
+}
+
+func (h *Handler) removeSeriesPart(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	blogId := mux.Vars(r)["blogId"]
+	if current, _ := h.store.GetSeriesByBlogId(blogId); current == nil || current.Id != s.Id {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog is not part of this series")
+		return
+	}
+
+	if err := h.store.RemoveSeriesPart(s.Id, blogId); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeriesById(w, r, s.Id)
+}
+
+func (h *Handler) reorderSeries(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types_blog.ReorderSeriesPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series order payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	parts, err := h.store.GetSeriesParts(s.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	current := []string{}
+	for _, p := range parts {
+		current = append(current, p.Id)
+	}
+
+	order := slices.Clone(payload.BlogIds)
+	slices.Sort(order)
+	slices.Sort(current)
+
+	if !slices.Equal(order, current) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The order must list every part of the series exactly once",
+		)
+		return
+	}
+
+	if err := h.store.ReorderSeries(s.Id, payload.BlogIds); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeriesById(w, r, s.Id)
+}
+
+func (h *Handler) moveSeriesPart(w http.ResponseWriter, r *http.Request) {
+	s, ok := h.ownSeries(w, r)
+	if !ok {
+		return
+	}
+
+	var payload types_blog.MoveSeriesPartPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid series part payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	parts, err := h.store.GetSeriesParts(s.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	blogId := mux.Vars(r)["blogId"]
+	order := []string{}
+	for _, p := range parts {
+		if p.Id != blogId {
+			order = append(order, p.Id)
+		}
+	}
+
+	if len(order) == len(parts) {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog is not part of this series")
+		return
+	}
+
+	if payload.Position > len(parts) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("The position must be between 1 and %d", len(parts)),
+		)
+		return
+	}
+
+	order = slices.Insert(order, payload.Position-1, blogId)
+
+	if err := h.store.ReorderSeries(s.Id, order); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeriesById(w, r, s.Id)
+}
+
+// ownSeries loads the series from the route and makes sure the current user
+// is allowed to change it.
+func (h *Handler) ownSeries(w http.ResponseWriter, r *http.Request) (*types_blog.Series, bool) {
+	s, err := h.store.GetSeriesById(mux.Vars(r)["id"])
+	if err != nil || s == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Series not found")
+		return nil, false
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	if s.AuthorId != userId && !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusForbidden,
+			"Only the author of the series can change it",
+		)
+		return nil, false
+	}
+
+	return s, true
+}
+
+func (h *Handler) writeSeriesById(w http.ResponseWriter, r *http.Request, id string) {
+	s, err := h.store.GetSeriesById(id)
+	if err != nil || s == nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	h.writeSeries(w, r, http.StatusOK, s)
+}
+
+func (h *Handler) writeSeries(
+	w http.ResponseWriter,
+	r *http.Request,
+	status int,
+	s *types_blog.Series,
+) {
+	parts, err := h.seriesParts(r, s.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	s.Parts = parts
+	s.PartCount = len(parts)
+
+	utils.WriteJSONInResponse(w, status, s, nil)
+}
+
+// seriesParts returns the parts of a series the current user can read, so
+// drafts and members-only posts don't leak through the listing or the
+// previous and next links.
+func (h *Handler) seriesParts(r *http.Request, seriesId string) ([]types_blog.Blog, error) {
+	parts, err := h.store.GetSeriesParts(seriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+
+	visible := []types_blog.Blog{}
+	for _, p := range parts {
+		if p.Status != types_blog.BlogStatusPublished && blogRole(&p, userId) == "" {
+			continue
+		}
+
+		if p.Visibility == types_blog.BlogVisibilityMembers && userId == "" {
+			continue
+		}
+
+		visible = append(visible, p)
+	}
+
+	return visible, nil
+}
+
+func (h *Handler) seriesNavigation(
+	r *http.Request,
+	b *types_blog.Blog,
+) *types_blog.SeriesNavigation {
+	s, err := h.store.GetSeriesByBlogId(b.Id)
+	if err != nil || s == nil {
+		return nil
+	}
+
+	parts, err := h.seriesParts(r, s.Id)
+	if err != nil {
+		return nil
+	}
+
+	i := slices.IndexFunc(parts, func(p types_blog.Blog) bool { return p.Id == b.Id })
+	if i < 0 {
+		return nil
+	}
+
+	nav := &types_blog.SeriesNavigation{
+		Id:       s.Id,
+		Title:    s.Title,
+		Slug:     s.Slug,
+		Position: i + 1,
+		Total:    len(parts),
+	}
+
+	if i > 0 {
+		nav.Previous = &types_blog.SeriesPart{
+			Id:       parts[i-1].Id,
+			Title:    parts[i-1].Title,
+			Slug:     parts[i-1].Slug,
+			Position: i,
+		}
+	}
+
+	if i < len(parts)-1 {
+		nav.Next = &types_blog.SeriesPart{
+			Id:       parts[i+1].Id,
+			Title:    parts[i+1].Title,
+			Slug:     parts[i+1].Slug,
+			Position: i + 2,
+		}
+	}
+
+	return nav
+}
@@ -0,0 +1,105 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	types_blog "github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+// reservedSlugs can't be used by blogs because they would shadow the routes
+// mounted next to /blog/{slug}.
+var reservedSlugs = map[string]bool{
+	"md":          true,
+	"image":       true,
+	"feed":        true,
+	"tags":        true,
+	"categories":  true,
+	"rss":         true,
+	"atom":        true,
+	"json":        true,
+	"sitemap":     true,
+	"robots":      true,
+	"series":      true,
+	"invitations": true,
+	"import":      true,
+	"new":         true,
+	"edit":        true,
+	"admin":       true,
+	"api":         true,
+}
+
+var (
+	errInvalidSlug  = fmt.Errorf("The slug must contain at least one letter or digit")
+	errReservedSlug = fmt.Errorf("This slug is reserved, please choose another one")
+	errTakenSlug    = fmt.Errorf("Another blog with that slug already exists")
+)
+
+// slugTaken also counts the old slugs of other blogs, since taking one would
+// break the redirect from its old address.
+func (h *Handler) slugTaken(slug string, blogId string) bool {
+	if b, _ := h.store.GetBlogBySlug(slug); b != nil && b.Id != blogId {
+		return true
+	}
+
+	b, _ := h.store.GetBlogByOldSlug(slug)
+	return b != nil && b.Id != blogId
+}
+
+// uniqueSlug derives a slug from the title and appends -2, -3, ... until it
+// doesn't collide with another blog or a reserved word.
+func (h *Handler) uniqueSlug(title string, blogId string) string {
+	base := utils.CreateSlug(title)
+	if base == "" {
+		base = "blog"
+	}
+
+	candidate := base
+	for n := 2; reservedSlugs[candidate] || h.slugTaken(candidate, blogId); n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return candidate
+}
+
+// customSlug validates a slug chosen by the author. Unlike derived slugs it is
+// never suffixed, since the author asked for that exact address.
+func (h *Handler) customSlug(slug string, blogId string) (string, error) {
+	slug = utils.CreateSlug(slug)
+
+	switch {
+	case slug == "":
+		return "", errInvalidSlug
+	case reservedSlugs[slug]:
+		return "", errReservedSlug
+	case h.slugTaken(slug, blogId):
+		return "", errTakenSlug
+	}
+
+	return slug, nil
+}
+
+// redirectOldSlug answers requests for a slug a blog used to have with a
+// permanent redirect to the same path under its current slug.
+func (h *Handler) redirectOldSlug(w http.ResponseWriter, r *http.Request, slug string) bool {
+	b, err := h.store.GetBlogByOldSlug(slug)
+	if err != nil || b == nil || b.Status != types_blog.BlogStatusPublished {
+		return false
+	}
+
+	i := strings.LastIndex(r.URL.Path, "/"+slug)
+	if i == -1 {
+		return false
+	}
+
+	location := url.URL{
+		Path:     r.URL.Path[:i+1] + b.Slug + r.URL.Path[i+1+len(slug):],
+		RawQuery: r.URL.RawQuery,
+	}
+
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+	return true
+}
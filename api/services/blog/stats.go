@@ -0,0 +1,155 @@
+package blog
+
+import (
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const (
+	wordsPerMinute  = 200
+	secondsPerImage = 12
+
+	// Rendering runs inline in the upload and edit requests, so anything bigger
+	// than this is estimated from the markdown source instead.
+	maxRenderedStatsSize = 512 * 1024
+)
+
+var (
+	statsHeadingRegex   = regexp.MustCompile(`<h[1-6][ >]`)
+	statsCodeBlockRegex = regexp.MustCompile(`(?s)<pre><code.*?</code></pre>`)
+	statsTagRegex       = regexp.MustCompile(`<[^>]*>`)
+)
+
+// readingStats counts what a reader will see, so the markdown is rendered
+// first: front matter and markup never count as words, and code blocks are
+// counted on their own instead of as prose. Tags are dropped without leaving a
+// space, since the renderer already ends every block with a newline and inline
+// tags shouldn't split the punctuation that follows them into another word.
+func readingStats(content string) types_blog.ReadingStats {
+	if _, body, err := utils.ParseFrontMatter(content); err == nil {
+		content = body
+	}
+
+	var stats types_blog.ReadingStats
+	if len(content) > maxRenderedStatsSize {
+		stats = estimateStats(content)
+	} else {
+		rendered, _ := utils.RenderMarkdown(content)
+
+		stats = types_blog.ReadingStats{
+			HeadingCount:   len(statsHeadingRegex.FindAllString(rendered, -1)),
+			ImageCount:     strings.Count(rendered, "<img "),
+			CodeBlockCount: len(statsCodeBlockRegex.FindAllString(rendered, -1)),
+		}
+
+		text := statsCodeBlockRegex.ReplaceAllString(rendered, " ")
+		text = html.UnescapeString(statsTagRegex.ReplaceAllString(text, ""))
+		stats.WordCount = len(strings.Fields(text))
+	}
+
+	seconds := (stats.WordCount*60+wordsPerMinute-1)/wordsPerMinute +
+		stats.ImageCount*secondsPerImage
+	if seconds > 0 {
+		stats.ReadingTime = (seconds + 59) / 60
+	}
+
+	return stats
+}
+
+// estimateStats counts the markdown source line by line in a single pass.
+// Markup such as emphasis or link targets may be counted as words, which is
+// close enough for a document this long.
+func estimateStats(content string) types_blog.ReadingStats {
+	stats := types_blog.ReadingStats{}
+	inCode := false
+
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			if !inCode {
+				stats.CodeBlockCount++
+			}
+			inCode = !inCode
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			stats.HeadingCount++
+			trimmed = strings.TrimLeft(trimmed, "#")
+		}
+
+		stats.ImageCount += strings.Count(trimmed, "![")
+		stats.WordCount += len(strings.Fields(trimmed))
+	}
+
+	return stats
+}
+
+// loadContent returns the markdown of a blog, preferring the content stored
+// with it and falling back to the uploaded file.
+func loadContent(
+	store types_blog.BlogStore,
+	mdFileUploadDir string,
+	id string,
+	mdFilename string,
+) (string, bool) {
+	if content, err := store.GetBlogContent(id); err == nil && content != nil {
+		return *content, true
+	}
+
+	content, err := os.ReadFile(filepath.Join(mdFileUploadDir, filepath.Base(mdFilename)))
+	if err != nil {
+		return "", false
+	}
+
+	return string(content), true
+}
+
+// BackfillReadingStats recomputes the reading statistics of every blog. Blogs
+// whose markdown can't be found are left untouched and returned so they can
+// be reported.
+func BackfillReadingStats(
+	store types_blog.BlogStore,
+	mdFileUploadDir string,
+	dryRun bool,
+) (int, []types_blog.BlogFiles, error) {
+	blogs, err := store.GetBlogFiles()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	updated := 0
+	skipped := []types_blog.BlogFiles{}
+
+	for _, b := range blogs {
+		content, ok := loadContent(store, mdFileUploadDir, b.BlogId, b.MDFilename)
+		if !ok {
+			skipped = append(skipped, b)
+			continue
+		}
+
+		if !dryRun {
+			if err := store.UpdateBlogStats(b.BlogId, readingStats(content)); err != nil {
+				log.Printf("failed to update the reading stats of %s: %v", b.BlogId, err)
+				skipped = append(skipped, b)
+				continue
+			}
+		}
+
+		updated++
+	}
+
+	return updated, skipped, nil
+}
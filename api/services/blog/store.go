@@ -2,9 +2,15 @@ package blog
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
 )
 
 type Store struct {
@@ -16,19 +22,67 @@ func NewStore(db *sql.DB) *Store {
 }
 
 func (s *Store) CreateBlog(blog types_blog.CreateBlogPayload) (*types_blog.Blog, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	rowId := ""
-	err := s.db.QueryRow(
-		"INSERT INTO blogs (title,description,slug,mdFilename,pictureName) VALUES ($1,$2,$3,$4,$5) RETURNING id;",
+	err = tx.QueryRow(
+		"INSERT INTO blogs (title,description,slug,mdFilename,pictureName,authorId,visibility,status,publishedAt,scheduledAt,expiresAt,categoryId,content,wordCount,readingTime,headingCount,imageCount,codeBlockCount,createdAt,updatedAt) VALUES ($1,$2,$3,$4,$5,NULLIF($6, '')::UUID,$7,$8,$9,$10,$11,NULLIF($12, '')::UUID,$13,$14,$15,$16,$17,$18,COALESCE($19, NOW()),COALESCE($19, NOW())) RETURNING id;",
 		blog.Title,
 		blog.Description,
 		blog.Slug,
 		blog.MDFilename,
 		blog.PictureName,
+		blog.AuthorId,
+		blog.Visibility,
+		blog.Status,
+		blog.PublishedAt,
+		blog.ScheduledAt,
+		blog.ExpiresAt,
+		blog.CategoryId,
+		blog.Content,
+		blog.Stats.WordCount,
+		blog.Stats.ReadingTime,
+		blog.Stats.HeadingCount,
+		blog.Stats.ImageCount,
+		blog.Stats.CodeBlockCount,
+		blog.CreatedAt,
 	).Scan(&rowId)
 	if err != nil {
 		return nil, err
 	}
 
+	if blog.AuthorId != "" {
+		_, err = tx.Exec(
+			"INSERT INTO blog_authors (blogId,userId,role) VALUES ($1,$2,$3);",
+			rowId,
+			blog.AuthorId,
+			types_blog.BlogAuthorRoleOwner,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := setBlogTags(tx, rowId, blog.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := updateSearchVector(tx, rowId, blog.Content); err != nil {
+		return nil, err
+	}
+
+	if err := insertRevision(tx, rowId, blog.Content, blog.AuthorId); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	b, err := s.GetBlogById(rowId)
 	if err != nil || b == nil {
 		return nil, err
@@ -37,32 +91,159 @@ func (s *Store) CreateBlog(blog types_blog.CreateBlogPayload) (*types_blog.Blog,
 	return b, nil
 }
 
-func (s *Store) GetBlogs(query types_blog.SearchBlogQuery) ([]types_blog.Blog, error) {
+func (s *Store) GetBlogs(query types_blog.SearchBlogQuery) ([]types_blog.Blog, int, error) {
+	conditions := []string{}
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	tsQuery := ""
+	if query.Keyword != "" {
+		tsQuery = fmt.Sprintf("websearch_to_tsquery('english', %s)", arg(query.Keyword))
+		conditions = append(conditions, fmt.Sprintf("searchVector @@ %s", tsQuery))
+	}
+
+	if query.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = %s", arg(query.Status)))
+	}
+
+	if query.AuthorId != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM blog_authors ba WHERE ba.blogId = blogs.id AND ba.userId = %s)",
+			arg(query.AuthorId),
+		))
+	}
+
+	if query.PublicOnly {
+		conditions = append(
+			conditions,
+			fmt.Sprintf("visibility = %s", arg(types_blog.BlogVisibilityPublic)),
+		)
+	}
+
+	if query.Tag != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tagId WHERE bt.blogId = blogs.id AND t.slug = %s)",
+			arg(query.Tag),
+		))
+	}
+
+	if query.Category != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"categoryId IN (WITH RECURSIVE tree AS (SELECT id FROM categories WHERE slug = %s UNION SELECT c.id FROM categories c JOIN tree ON c.parentId = tree.id) SELECT id FROM tree)",
+			arg(query.Category),
+		))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+	}
+
+	total := 0
+	err := s.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM blogs %s;", where), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	direction := "DESC"
+	if query.SortOrder == "asc" {
+		direction = "ASC"
+	}
+
+	orderBy := ""
+	if query.SortBy == "relevance" && tsQuery != "" {
+		orderBy = fmt.Sprintf("rank %s, id %s", direction, direction)
+	} else {
+		sortColumn := "createdAt"
+		switch query.SortBy {
+		case "", "relevance":
+		case "publishedAt":
+			sortColumn = "COALESCE(publishedAt, createdAt)"
+		default:
+			sortColumn = query.SortBy
+		}
+
+		if query.Cursor != "" {
+			cursor, err := utils.DecodeCursor(query.Cursor)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			comparison := "<"
+			if direction == "ASC" {
+				comparison = ">"
+			}
+
+			conditions = append(conditions, fmt.Sprintf(
+				"(%s, id) %s (%s, %s)",
+				sortColumn,
+				comparison,
+				arg(cursor.Value),
+				arg(cursor.Id),
+			))
+			where = fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+		}
+
+		orderBy = fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)
+	}
+
+	columns := blogColumns("")
+	if tsQuery != "" {
+		columns = fmt.Sprintf(
+			"%s, ts_rank(searchVector, %s) AS rank, ts_headline('english', replace(replace(replace(coalesce(NULLIF(content, ''), description), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), %s, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10') AS snippet",
+			columns,
+			tsQuery,
+			tsQuery,
+		)
+	}
+
+	pagination := fmt.Sprintf("LIMIT %s", arg(query.Limit))
+	if query.Cursor == "" {
+		pagination = fmt.Sprintf("%s OFFSET %s", pagination, arg((query.Page-1)*query.Limit))
+	}
+
 	rows, err := s.db.Query(
-		"SELECT * FROM blogs WHERE title ILIKE $1 OR description ILIKE $2;",
-		fmt.Sprintf("%%%s%%", query.Keyword),
-		fmt.Sprintf("%%%s%%", query.Keyword),
+		fmt.Sprintf("SELECT %s FROM blogs %s ORDER BY %s %s;", columns, where, orderBy, pagination),
+		args...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
 	blogs := []types_blog.Blog{}
 
 	for rows.Next() {
-		blog, err := scanRow(rows)
+		var blog *types_blog.Blog
+		var rank float64
+		var snippet string
+
+		if tsQuery != "" {
+			blog, err = scanRow(rows, &rank, &snippet)
+		} else {
+			blog, err = scanRow(rows)
+		}
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
+		blog.Rank = rank
+		blog.Snippet = snippet
+
 		blogs = append(blogs, *blog)
 	}
 
-	return blogs, nil
+	return blogs, total, nil
 }
 
 func (s *Store) GetBlogById(id string) (*types_blog.Blog, error) {
-	rows, err := s.db.Query("SELECT * FROM blogs WHERE id = $1;", id)
+	rows, err := s.db.Query(
+		fmt.Sprintf("SELECT %s FROM blogs WHERE id = $1;", blogColumns("")),
+		id,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +265,10 @@ func (s *Store) GetBlogById(id string) (*types_blog.Blog, error) {
 }
 
 func (s *Store) GetBlogBySlug(slug string) (*types_blog.Blog, error) {
-	rows, err := s.db.Query("SELECT * FROM blogs WHERE slug = $1;", slug)
+	rows, err := s.db.Query(
+		fmt.Sprintf("SELECT %s FROM blogs WHERE slug = $1;", blogColumns("")),
+		slug,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -105,26 +289,203 @@ func (s *Store) GetBlogBySlug(slug string) (*types_blog.Blog, error) {
 	return blog, nil
 }
 
+func (s *Store) GetBlogByOldSlug(slug string) (*types_blog.Blog, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT %s FROM blogs WHERE id = (SELECT blogId FROM slug_history WHERE slug = $1);",
+			blogColumns(""),
+		),
+		slug,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanRow(rows)
+	}
+
+	return nil, fmt.Errorf("Blog not found")
+}
+
+func (s *Store) GetBlogContent(id string) (*string, error) {
+	var content sql.NullString
+
+	err := s.db.QueryRow("SELECT content FROM blogs WHERE id = $1;", id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !content.Valid {
+		return nil, nil
+	}
+
+	return &content.String, nil
+}
+
 func (s *Store) UpdateBlog(id string, blog types_blog.UpdateBlogPayload) error {
-	_, err := s.db.Exec(
-		"UPDATE blogs SET title = $1, description = $2, slug = $3, pictureName = $4, mdFilename = $5, updatedAt = $6 WHERE id = $7",
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO blog_revisions (blogId,revision,title,description,slug,pictureName,mdFilename,content,editorId) SELECT id, 1, title, description, slug, pictureName, mdFilename, $2, authorId FROM blogs WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM blog_revisions WHERE blogId = $1);",
+		id,
+		blog.PreviousContent,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO slug_history (slug,blogId) SELECT slug, id FROM blogs WHERE id = $1 AND slug <> $2 ON CONFLICT (slug) DO UPDATE SET blogId = EXCLUDED.blogId, createdAt = NOW();",
+		id,
+		blog.Slug,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM slug_history WHERE slug = $1 AND blogId = $2;", blog.Slug, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"UPDATE blogs SET title = $1, description = $2, slug = $3, pictureName = $4, mdFilename = $5, visibility = $6, updatedAt = $7, categoryId = NULLIF($8, '')::UUID, content = $9, wordCount = $10, readingTime = $11, headingCount = $12, imageCount = $13, codeBlockCount = $14 WHERE id = $15",
 		blog.Title,
 		blog.Description,
 		blog.Slug,
 		blog.PictureName,
 		blog.MDFilename,
+		blog.Visibility,
 		blog.UpdatedAt,
+		blog.CategoryId,
+		blog.Content,
+		blog.Stats.WordCount,
+		blog.Stats.ReadingTime,
+		blog.Stats.HeadingCount,
+		blog.Stats.ImageCount,
+		blog.Stats.CodeBlockCount,
 		id,
 	)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if blog.Tags != nil {
+		if err := setBlogTags(tx, id, blog.Tags); err != nil {
+			return err
+		}
+	}
+
+	content := ""
+	if blog.Content != nil {
+		content = *blog.Content
+	}
+
+	if err := updateSearchVector(tx, id, content); err != nil {
+		return err
+	}
+
+	if err := insertRevision(tx, id, content, blog.EditorId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// DeleteBlogById also renumbers the series the blog was part of, since its
+// series_blogs row goes away with it.
 func (s *Store) DeleteBlogById(id string) error {
-	_, err := s.db.Exec("DELETE FROM blogs WHERE id = $1;", id)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var seriesId sql.NullString
+	err = tx.QueryRow("SELECT seriesId FROM series_blogs WHERE blogId = $1;", id).Scan(&seriesId)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM blogs WHERE id = $1;", id); err != nil {
+		return err
+	}
+
+	if seriesId.Valid {
+		if err := renumberSeries(tx, seriesId.String); err != nil {
+			return err
+		}
+
+		if err := touchSeries(tx, seriesId.String); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) GetFeed(query types_blog.FeedQuery) ([]types_blog.Blog, error) {
+	conditions := []string{
+		"b.status = 'published'",
+		"EXISTS (SELECT 1 FROM blog_authors ba JOIN follows f ON f.followeeId = ba.userId WHERE ba.blogId = b.id AND ba.role IN ('owner', 'coauthor') AND f.followerId = $1)",
+	}
+	args := []any{query.UserId}
+
+	if query.Cursor != "" {
+		cursor, err := utils.DecodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, cursor.Value, cursor.Id)
+		conditions = append(conditions, "(b.publishedAt, b.id) < ($2, $3)")
+	}
+
+	args = append(args, query.Limit)
+
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT %s FROM blogs b WHERE %s ORDER BY b.publishedAt DESC, b.id DESC LIMIT $%d;",
+			blogColumns("b"),
+			strings.Join(conditions, " AND "),
+			len(args),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := []types_blog.Blog{}
+
+	for rows.Next() {
+		blog, err := scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		blogs = append(blogs, *blog)
+	}
+
+	return blogs, nil
+}
+
+func (s *Store) UpdateBlogStatus(id string, status types_blog.UpdateBlogStatusPayload) error {
+	_, err := s.db.Exec(
+		"UPDATE blogs SET status = $1, publishedAt = $2, scheduledAt = $3, expiresAt = $4 WHERE id = $5;",
+		status.Status,
+		status.PublishedAt,
+		status.ScheduledAt,
+		status.ExpiresAt,
+		id,
+	)
 	if err != nil {
 		return err
 	}
@@ -132,22 +493,1109 @@ func (s *Store) DeleteBlogById(id string) error {
 	return nil
 }
 
-func scanRow(rows *sql.Rows) (*types_blog.Blog, error) {
-	blog := new(types_blog.Blog)
+func (s *Store) PublishScheduledBlogs(now time.Time) (int64, error) {
+	res, err := s.db.Exec(
+		"UPDATE blogs SET status = 'published', publishedAt = scheduledAt, scheduledAt = NULL WHERE status = 'scheduled' AND scheduledAt <= $1;",
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (s *Store) UnpublishExpiredBlogs(now time.Time) (int64, error) {
+	res, err := s.db.Exec(
+		"UPDATE blogs SET status = 'draft', expiresAt = NULL WHERE status = 'published' AND expiresAt IS NOT NULL AND expiresAt <= $1;",
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (s *Store) GetBlogRevisions(blogId string) ([]types_blog.BlogRevision, error) {
+	rows, err := s.db.Query(
+		"SELECT id, blogId, revision, title, description, slug, pictureName, mdFilename, '', editorId, createdAt FROM blog_revisions WHERE blogId = $1 ORDER BY revision DESC;",
+		blogId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []types_blog.BlogRevision{}
+
+	for rows.Next() {
+		revision, err := scanRevisionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, *revision)
+	}
+
+	return revisions, nil
+}
+
+func (s *Store) GetBlogRevision(blogId string, revision int) (*types_blog.BlogRevision, error) {
+	rows, err := s.db.Query(
+		"SELECT id, blogId, revision, title, description, slug, pictureName, mdFilename, content, editorId, createdAt FROM blog_revisions WHERE blogId = $1 AND revision = $2;",
+		blogId,
+		revision,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanRevisionRow(rows)
+	}
+
+	return nil, fmt.Errorf("Revision not found")
+}
+
+func insertRevision(tx *sql.Tx, id string, content string, editorId string) error {
+	_, err := tx.Exec(
+		"INSERT INTO blog_revisions (blogId,revision,title,description,slug,pictureName,mdFilename,content,editorId) SELECT id, COALESCE((SELECT MAX(revision) FROM blog_revisions WHERE blogId = $1), 0) + 1, title, description, slug, pictureName, mdFilename, $2, NULLIF($3, '')::UUID FROM blogs WHERE id = $1;",
+		id,
+		content,
+		editorId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanRevisionRow(rows *sql.Rows) (*types_blog.BlogRevision, error) {
+	revision := new(types_blog.BlogRevision)
+	var editorId sql.NullString
 
 	err := rows.Scan(
-		&blog.Id,
-		&blog.Title,
-		&blog.Description,
-		&blog.Slug,
-		&blog.PictureName,
-		&blog.MDFilename,
-		&blog.CreatedAt,
-		&blog.UpdatedAt,
+		&revision.Id,
+		&revision.BlogId,
+		&revision.Revision,
+		&revision.Title,
+		&revision.Description,
+		&revision.Slug,
+		&revision.PictureName,
+		&revision.MDFilename,
+		&revision.Content,
+		&editorId,
+		&revision.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return blog, nil
+	revision.EditorId = editorId.String
+
+	return revision, nil
+}
+
+func (s *Store) GetTags() ([]types_blog.Tag, error) {
+	rows, err := s.db.Query(
+		"SELECT t.id, t.name, t.slug, COUNT(b.id) AS postCount FROM tags t LEFT JOIN blog_tags bt ON bt.tagId = t.id LEFT JOIN blogs b ON b.id = bt.blogId AND b.status = 'published' GROUP BY t.id ORDER BY postCount DESC, t.name ASC;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []types_blog.Tag{}
+
+	for rows.Next() {
+		var tag types_blog.Tag
+
+		err := rows.Scan(&tag.Id, &tag.Name, &tag.Slug, &tag.PostCount)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func (s *Store) GetTagBySlug(slug string) (*types_blog.Tag, error) {
+	tag := new(types_blog.Tag)
+
+	err := s.db.QueryRow(
+		"SELECT id, name, slug FROM tags WHERE slug = $1;",
+		slug,
+	).Scan(&tag.Id, &tag.Name, &tag.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("Tag not found")
+		}
+
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *Store) RenameTag(id string, name string, slug string) error {
+	_, err := s.db.Exec("UPDATE tags SET name = $1, slug = $2 WHERE id = $3;", name, slug, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) MergeTags(sourceId string, targetId string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO blog_tags (blogId,tagId) SELECT blogId, $2 FROM blog_tags WHERE tagId = $1 ON CONFLICT DO NOTHING;",
+		sourceId,
+		targetId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = $1;", sourceId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) GetCategories() ([]types_blog.Category, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, slug, parentId, createdAt FROM categories ORDER BY name ASC;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []types_blog.Category{}
+
+	for rows.Next() {
+		category, err := scanCategoryRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, *category)
+	}
+
+	return categories, nil
+}
+
+func (s *Store) GetCategoryById(id string) (*types_blog.Category, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, slug, parentId, createdAt FROM categories WHERE id = $1;",
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanCategoryRow(rows)
+	}
+
+	return nil, fmt.Errorf("Category not found")
+}
+
+func (s *Store) GetCategoryBySlug(slug string) (*types_blog.Category, error) {
+	rows, err := s.db.Query(
+		"SELECT id, name, slug, parentId, createdAt FROM categories WHERE slug = $1;",
+		slug,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanCategoryRow(rows)
+	}
+
+	return nil, fmt.Errorf("Category not found")
+}
+
+func (s *Store) CreateCategory(
+	category types_blog.CreateCategoryPayload,
+) (*types_blog.Category, error) {
+	rowId := ""
+	err := s.db.QueryRow(
+		"INSERT INTO categories (name,slug,parentId) VALUES ($1,$2,NULLIF($3, '')::UUID) RETURNING id;",
+		category.Name,
+		category.Slug,
+		category.ParentId,
+	).Scan(&rowId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategoryById(rowId)
+}
+
+func (s *Store) ToggleReaction(blogId string, userId string, reaction string) (bool, error) {
+	res, err := s.db.Exec(
+		"DELETE FROM blog_reactions WHERE blogId = $1 AND userId = $2 AND reaction = $3;",
+		blogId,
+		userId,
+		reaction,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	if deleted, err := res.RowsAffected(); err != nil || deleted > 0 {
+		return false, err
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO blog_reactions (blogId,userId,reaction) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING;",
+		blogId,
+		userId,
+		reaction,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Store) GetReactionCounts(blogId string) (map[string]int, error) {
+	rows, err := s.db.Query(
+		"SELECT reaction, COUNT(*) FROM blog_reactions WHERE blogId = $1 GROUP BY reaction;",
+		blogId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+
+	for rows.Next() {
+		reaction := ""
+		count := 0
+
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+
+		counts[reaction] = count
+	}
+
+	return counts, nil
+}
+
+func (s *Store) RecordViews(views []types_blog.BlogView) (int64, error) {
+	blogIds := []string{}
+	visitorIds := []string{}
+	days := []string{}
+
+	for _, v := range views {
+		blogIds = append(blogIds, v.BlogId)
+		visitorIds = append(visitorIds, v.VisitorId)
+		days = append(days, v.Day)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var counted int64
+	err = tx.QueryRow(
+		"WITH inserted AS (INSERT INTO blog_views (blogId,visitorId,day) SELECT v.blogId, v.visitorId, v.day FROM unnest($1::UUID[], $2::VARCHAR[], $3::DATE[]) AS v(blogId, visitorId, day) WHERE EXISTS (SELECT 1 FROM blogs WHERE id = v.blogId) ON CONFLICT DO NOTHING RETURNING blogId), counts AS (SELECT blogId, COUNT(*) AS count FROM inserted GROUP BY blogId), updated AS (UPDATE blogs SET viewCount = blogs.viewCount + counts.count FROM counts WHERE blogs.id = counts.blogId RETURNING counts.count) SELECT COALESCE(SUM(count), 0) FROM updated;",
+		pq.Array(blogIds),
+		pq.Array(visitorIds),
+		pq.Array(days),
+	).Scan(&counted)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("DELETE FROM blog_views WHERE day < CURRENT_DATE - 1;")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return counted, nil
+}
+
+func setBlogTags(tx *sql.Tx, id string, tags []string) error {
+	names := []string{}
+	slugs := []string{}
+
+	for _, name := range tags {
+		name = strings.TrimSpace(name)
+		slug := utils.CreateSlug(name)
+		if slug == "" {
+			continue
+		}
+
+		names = append(names, name)
+		slugs = append(slugs, slug)
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO tags (name,slug) SELECT DISTINCT ON (slug) name, slug FROM unnest($1::VARCHAR[], $2::VARCHAR[]) AS t(name, slug) ON CONFLICT (slug) DO NOTHING;",
+		pq.Array(names),
+		pq.Array(slugs),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM blog_tags WHERE blogId = $1;", id); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO blog_tags (blogId,tagId) SELECT $1, id FROM tags WHERE slug = ANY($2::VARCHAR[]);",
+		id,
+		pq.Array(slugs),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const seriesColumns = "s.id, s.title, s.slug, s.description, s.authorId, (SELECT COUNT(*) FROM series_blogs sb WHERE sb.seriesId = s.id), s.createdAt, s.updatedAt"
+
+func scanCategoryRow(rows *sql.Rows) (*types_blog.Category, error) {
+	category := new(types_blog.Category)
+	var parentId sql.NullString
+
+	err := rows.Scan(
+		&category.Id,
+		&category.Name,
+		&category.Slug,
+		&parentId,
+		&category.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	category.ParentId = parentId.String
+
+	return category, nil
+}
+
+func updateSearchVector(tx *sql.Tx, id string, content string) error {
+	_, err := tx.Exec(
+		"UPDATE blogs SET searchVector = setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(description, '')), 'B') || setweight(to_tsvector('english', $1), 'C') WHERE id = $2;",
+		content,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func blogColumns(alias string) string {
+	columns := []string{
+		"id",
+		"title",
+		"description",
+		"slug",
+		"pictureName",
+		"mdFilename",
+		"createdAt",
+		"updatedAt",
+		"authorId",
+		"visibility",
+		"status",
+		"publishedAt",
+		"scheduledAt",
+		"expiresAt",
+		"categoryId",
+		"viewCount",
+		"wordCount",
+		"readingTime",
+		"headingCount",
+		"imageCount",
+		"codeBlockCount",
+	}
+
+	table := "blogs"
+	if alias != "" {
+		table = alias
+
+		for i := range columns {
+			columns[i] = fmt.Sprintf("%s.%s", alias, columns[i])
+		}
+	}
+
+	columns = append(columns, fmt.Sprintf(
+		"COALESCE((SELECT json_agg(json_build_object('id', t.id, 'name', t.name, 'slug', t.slug) ORDER BY t.name) FROM blog_tags bt JOIN tags t ON t.id = bt.tagId WHERE bt.blogId = %s.id), '[]')",
+		table,
+	))
+
+	columns = append(columns, fmt.Sprintf(
+		"COALESCE((SELECT json_agg(json_build_object('userId', ba.userId, 'username', u.username, 'role', ba.role) ORDER BY ba.role = 'owner' DESC, ba.createdAt) FROM blog_authors ba JOIN users u ON u.id = ba.userId WHERE ba.blogId = %s.id), '[]')",
+		table,
+	))
+
+	columns = append(columns, fmt.Sprintf(
+		"COALESCE((SELECT json_object_agg(r.reaction, r.count) FROM (SELECT reaction, COUNT(*) AS count FROM blog_reactions WHERE blogId = %s.id GROUP BY reaction) r), '{}')",
+		table,
+	))
+
+	return strings.Join(columns, ", ")
+}
+
+func scanRow(rows *sql.Rows, extra ...any) (*types_blog.Blog, error) {
+	blog := new(types_blog.Blog)
+	var authorId, categoryId sql.NullString
+	var publishedAt, scheduledAt, expiresAt sql.NullTime
+	var tags, authors, reactions []byte
+
+	dest := []any{
+		&blog.Id,
+		&blog.Title,
+		&blog.Description,
+		&blog.Slug,
+		&blog.PictureName,
+		&blog.MDFilename,
+		&blog.CreatedAt,
+		&blog.UpdatedAt,
+		&authorId,
+		&blog.Visibility,
+		&blog.Status,
+		&publishedAt,
+		&scheduledAt,
+		&expiresAt,
+		&categoryId,
+		&blog.ViewCount,
+		&blog.Stats.WordCount,
+		&blog.Stats.ReadingTime,
+		&blog.Stats.HeadingCount,
+		&blog.Stats.ImageCount,
+		&blog.Stats.CodeBlockCount,
+		&tags,
+		&authors,
+		&reactions,
+	}
+
+	err := rows.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(tags, &blog.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(authors, &blog.Authors); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(reactions, &blog.Reactions); err != nil {
+		return nil, err
+	}
+
+	blog.AuthorId = authorId.String
+	blog.CategoryId = categoryId.String
+
+	if publishedAt.Valid {
+		blog.PublishedAt = &publishedAt.Time
+	}
+
+	if scheduledAt.Valid {
+		blog.ScheduledAt = &scheduledAt.Time
+	}
+
+	if expiresAt.Valid {
+		blog.ExpiresAt = &expiresAt.Time
+	}
+
+	return blog, nil
+}
+
+func (s *Store) GetBlogFiles() ([]types_blog.BlogFiles, error) {
+	return s.queryFiles("SELECT id, slug, mdFilename, pictureName FROM blogs;")
+}
+
+func (s *Store) GetRevisionFiles() ([]types_blog.BlogFiles, error) {
+	return s.queryFiles(
+		"SELECT DISTINCT blogId, slug, mdFilename, pictureName FROM blog_revisions;",
+	)
+}
+
+// GetContentImageNames returns the uploaded images linked from the content of
+// the blogs and their revisions, still escaped as they appear in the links.
+func (s *Store) GetContentImageNames() ([]string, error) {
+	rows, err := s.db.Query(
+		"SELECT DISTINCT (regexp_matches(c.content, '/api/v1/blog/image/([^])[:space:]\"''<>?#]+)', 'g'))[1] FROM (SELECT content FROM blogs WHERE content IS NOT NULL UNION ALL SELECT content FROM blog_revisions WHERE content IS NOT NULL) c;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+
+	for rows.Next() {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+func (s *Store) queryFiles(query string) ([]types_blog.BlogFiles, error) {
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []types_blog.BlogFiles{}
+
+	for rows.Next() {
+		f := types_blog.BlogFiles{}
+
+		if err := rows.Scan(&f.BlogId, &f.Slug, &f.MDFilename, &f.PictureName); err != nil {
+			return nil, err
+		}
+
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+func (s *Store) CountSitemapEntries() (int, error) {
+	total := 0
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM blogs WHERE status = $1 AND visibility = $2;",
+		types_blog.BlogStatusPublished,
+		types_blog.BlogVisibilityPublic,
+	).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (s *Store) GetSitemapEntries(offset int, limit int) ([]types_blog.SitemapEntry, error) {
+	rows, err := s.db.Query(
+		"SELECT slug, COALESCE(updatedAt, publishedAt, createdAt) FROM blogs WHERE status = $1 AND visibility = $2 ORDER BY createdAt, id LIMIT $3 OFFSET $4;",
+		types_blog.BlogStatusPublished,
+		types_blog.BlogVisibilityPublic,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []types_blog.SitemapEntry{}
+
+	for rows.Next() {
+		e := types_blog.SitemapEntry{}
+
+		if err := rows.Scan(&e.Slug, &e.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetRelatedBlogs scores the other published blogs against the given one. The
+// most frequent lexemes of its search vector are matched against every
+// candidate, then shared tags, a shared category and recency are added, so
+// related posts still show up for blogs with little text.
+func (s *Store) GetRelatedBlogs(blogId string, limit int) ([]types_blog.Blog, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"WITH source AS (SELECT id, categoryId, searchVector FROM blogs WHERE id = $1), terms AS (SELECT to_tsquery('simple', string_agg(quote_literal(lexeme), ' | ')) AS query FROM (SELECT lexeme FROM source, unnest(source.searchVector) ORDER BY COALESCE(array_length(positions, 1), 0) DESC, lexeme LIMIT 32) AS top) SELECT %s, (COALESCE(ts_rank(b.searchVector, terms.query, 32), 0) * 0.5 + LEAST((SELECT COUNT(*) FROM blog_tags bt JOIN blog_tags st ON st.tagId = bt.tagId AND st.blogId = source.id WHERE bt.blogId = b.id), 4) / 4.0 * 0.25 + CASE WHEN b.categoryId IS NOT NULL AND b.categoryId = source.categoryId THEN 0.1 ELSE 0 END + 0.15 / (1 + EXTRACT(EPOCH FROM NOW() - COALESCE(b.publishedAt, b.createdAt)) / 2592000.0))::FLOAT AS score FROM blogs b CROSS JOIN source LEFT JOIN terms ON TRUE WHERE b.id <> source.id AND b.status = $2 AND b.visibility = $3 ORDER BY score DESC, b.id LIMIT $4;",
+			blogColumns("b"),
+		),
+		blogId,
+		types_blog.BlogStatusPublished,
+		types_blog.BlogVisibilityPublic,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := []types_blog.Blog{}
+
+	for rows.Next() {
+		var score float64
+
+		blog, err := scanRow(rows, &score)
+		if err != nil {
+			return nil, err
+		}
+
+		blog.Rank = score
+		blogs = append(blogs, *blog)
+	}
+
+	return blogs, rows.Err()
+}
+
+func (s *Store) GetSeries() ([]types_blog.Series, error) {
+	return s.querySeries(
+		fmt.Sprintf("SELECT %s FROM series s ORDER BY s.createdAt DESC;", seriesColumns),
+	)
+}
+
+func (s *Store) GetSeriesById(id string) (*types_blog.Series, error) {
+	return s.getSeriesBy("s.id = $1", id)
+}
+
+func (s *Store) GetSeriesBySlug(slug string) (*types_blog.Series, error) {
+	return s.getSeriesBy("s.slug = $1", slug)
+}
+
+func (s *Store) GetSeriesByBlogId(blogId string) (*types_blog.Series, error) {
+	return s.getSeriesBy(
+		"s.id = (SELECT seriesId FROM series_blogs WHERE blogId = $1)",
+		blogId,
+	)
+}
+
+func (s *Store) GetSeriesParts(seriesId string) ([]types_blog.Blog, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT %s FROM series_blogs sb JOIN blogs b ON b.id = sb.blogId WHERE sb.seriesId = $1 ORDER BY sb.position ASC;",
+			blogColumns("b"),
+		),
+		seriesId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blogs := []types_blog.Blog{}
+
+	for rows.Next() {
+		blog, err := scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		blogs = append(blogs, *blog)
+	}
+
+	return blogs, rows.Err()
+}
+
+func (s *Store) CreateSeries(series types_blog.CreateSeriesPayload) (*types_blog.Series, error) {
+	rowId := ""
+	err := s.db.QueryRow(
+		"INSERT INTO series (title,slug,description,authorId) VALUES ($1,$2,$3,NULLIF($4, '')::UUID) RETURNING id;",
+		series.Title,
+		series.Slug,
+		series.Description,
+		series.AuthorId,
+	).Scan(&rowId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSeriesById(rowId)
+}
+
+func (s *Store) UpdateSeries(id string, series types_blog.UpdateSeriesPayload) error {
+	_, err := s.db.Exec(
+		"UPDATE series SET title = $1, slug = $2, description = $3, updatedAt = NOW() WHERE id = $4;",
+		series.Title,
+		series.Slug,
+		series.Description,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteSeries(id string) error {
+	_, err := s.db.Exec("DELETE FROM series WHERE id = $1;", id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddSeriesPart inserts the blog at the given position, shifting the later
+// parts down. A position of 0 or past the last part appends it.
+func (s *Store) AddSeriesPart(seriesId string, blogId string, position int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	count := 0
+	err = tx.QueryRow(
+		"SELECT COUNT(*) FROM series_blogs WHERE seriesId = $1;",
+		seriesId,
+	).Scan(&count)
+	if err != nil {
+		return err
+	}
+
+	if position < 1 || position > count {
+		position = count + 1
+	}
+
+	_, err = tx.Exec(
+		"UPDATE series_blogs SET position = position + 1 WHERE seriesId = $1 AND position >= $2;",
+		seriesId,
+		position,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO series_blogs (seriesId,blogId,position) VALUES ($1,$2,$3);",
+		seriesId,
+		blogId,
+		position,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := touchSeries(tx, seriesId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) RemoveSeriesPart(seriesId string, blogId string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"DELETE FROM series_blogs WHERE seriesId = $1 AND blogId = $2;",
+		seriesId,
+		blogId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := renumberSeries(tx, seriesId); err != nil {
+		return err
+	}
+
+	if err := touchSeries(tx, seriesId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) ReorderSeries(seriesId string, blogIds []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, blogId := range blogIds {
+		_, err := tx.Exec(
+			"UPDATE series_blogs SET position = $1 WHERE seriesId = $2 AND blogId = $3;",
+			i+1,
+			seriesId,
+			blogId,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := renumberSeries(tx, seriesId); err != nil {
+		return err
+	}
+
+	if err := touchSeries(tx, seriesId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) getSeriesBy(condition string, value string) (*types_blog.Series, error) {
+	series, err := s.querySeries(
+		fmt.Sprintf("SELECT %s FROM series s WHERE %s;", seriesColumns, condition),
+		value,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(series) == 0 {
+		return nil, fmt.Errorf("Series not found")
+	}
+
+	return &series[0], nil
+}
+
+func (s *Store) querySeries(query string, args ...any) ([]types_blog.Series, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []types_blog.Series{}
+
+	for rows.Next() {
+		item := types_blog.Series{}
+		var authorId sql.NullString
+
+		err := rows.Scan(
+			&item.Id,
+			&item.Title,
+			&item.Slug,
+			&item.Description,
+			&authorId,
+			&item.PartCount,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		item.AuthorId = authorId.String
+		series = append(series, item)
+	}
+
+	return series, rows.Err()
+}
+
+// renumberSeries closes the gaps left by removed or deleted blogs, so the
+// positions of a series always run from 1 without holes.
+func renumberSeries(tx *sql.Tx, seriesId string) error {
+	_, err := tx.Exec(
+		"UPDATE series_blogs sb SET position = o.position FROM (SELECT blogId, ROW_NUMBER() OVER (ORDER BY position) AS position FROM series_blogs WHERE seriesId = $1) o WHERE sb.seriesId = $1 AND sb.blogId = o.blogId AND sb.position <> o.position;",
+		seriesId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func touchSeries(tx *sql.Tx, seriesId string) error {
+	_, err := tx.Exec("UPDATE series SET updatedAt = NOW() WHERE id = $1;", seriesId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) CreateBlogInvitation(
+	invitation types_blog.InviteBlogAuthorPayload,
+) (*types_blog.BlogInvitation, error) {
+	rowId := ""
+	err := s.db.QueryRow(
+		"INSERT INTO blog_invitations (blogId,inviterId,inviteeId,role) VALUES ($1,NULLIF($2, '')::UUID,$3,$4) RETURNING id;",
+		invitation.BlogId,
+		invitation.InviterId,
+		invitation.InviteeId,
+		invitation.Role,
+	).Scan(&rowId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetBlogInvitationById(rowId)
+}
+
+func (s *Store) GetBlogInvitationById(id string) (*types_blog.BlogInvitation, error) {
+	invitations, err := s.queryInvitations("i.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invitations) == 0 {
+		return nil, fmt.Errorf("Invitation not found")
+	}
+
+	return &invitations[0], nil
+}
+
+func (s *Store) GetPendingBlogInvitations(userId string) ([]types_blog.BlogInvitation, error) {
+	return s.queryInvitations(
+		"i.inviteeId = $1 AND i.status = $2",
+		userId,
+		types_blog.BlogInvitationStatusPending,
+	)
+}
+
+// RespondToBlogInvitation closes a pending invitation. Accepting it adds the
+// invitee to the authors of the blog, replacing any role they had before.
+func (s *Store) RespondToBlogInvitation(id string, accepted bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status := types_blog.BlogInvitationStatusDeclined
+	if accepted {
+		status = types_blog.BlogInvitationStatusAccepted
+	}
+
+	res, err := tx.Exec(
+		"UPDATE blog_invitations SET status = $1, respondedAt = NOW() WHERE id = $2 AND status = $3;",
+		status,
+		id,
+		types_blog.BlogInvitationStatusPending,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+		return fmt.Errorf("Invitation not found")
+	}
+
+	if accepted {
+		_, err = tx.Exec(
+			"INSERT INTO blog_authors (blogId,userId,role) SELECT blogId, inviteeId, role FROM blog_invitations WHERE id = $1 ON CONFLICT (blogId, userId) DO UPDATE SET role = EXCLUDED.role WHERE blog_authors.role <> 'owner';",
+			id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) RemoveBlogAuthor(blogId string, userId string) error {
+	_, err := s.db.Exec(
+		"DELETE FROM blog_authors WHERE blogId = $1 AND userId = $2 AND role <> $3;",
+		blogId,
+		userId,
+		types_blog.BlogAuthorRoleOwner,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) queryInvitations(
+	condition string,
+	args ...any,
+) ([]types_blog.BlogInvitation, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT i.id, i.blogId, b.title, i.inviterId, i.inviteeId, i.role, i.status, i.createdAt, i.respondedAt FROM blog_invitations i JOIN blogs b ON b.id = i.blogId WHERE %s ORDER BY i.createdAt DESC;",
+			condition,
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []types_blog.BlogInvitation{}
+
+	for rows.Next() {
+		invitation := types_blog.BlogInvitation{}
+		var inviterId sql.NullString
+		var respondedAt sql.NullTime
+
+		err := rows.Scan(
+			&invitation.Id,
+			&invitation.BlogId,
+			&invitation.BlogTitle,
+			&inviterId,
+			&invitation.InviteeId,
+			&invitation.Role,
+			&invitation.Status,
+			&invitation.CreatedAt,
+			&respondedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		invitation.InviterId = inviterId.String
+		if respondedAt.Valid {
+			invitation.RespondedAt = &respondedAt.Time
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (s *Store) UpdateBlogStats(id string, stats types_blog.ReadingStats) error {
+	_, err := s.db.Exec(
+		"UPDATE blogs SET wordCount = $1, readingTime = $2, headingCount = $3, imageCount = $4, codeBlockCount = $5 WHERE id = $6;",
+		stats.WordCount,
+		stats.ReadingTime,
+		stats.HeadingCount,
+		stats.ImageCount,
+		stats.CodeBlockCount,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
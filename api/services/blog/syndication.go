@@ -0,0 +1,551 @@
+package blog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const (
+	siteName            = "MegaVault"
+	syndicationFeedSize = 50
+)
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Guid        rssGuid       `xml:"guid"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Author      string        `xml:"author,omitempty"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Content     *rssContent   `xml:"content:encoded"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Id         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomAuthor    `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary"`
+	Content    *atomContent   `xml:"content"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	Id            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	Summary       string               `json:"summary"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	DateModified  string               `json:"date_modified"`
+	Tags          []string             `json:"tags,omitempty"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size_in_bytes,omitempty"`
+}
+
+// syndicationEntry is a blog prepared once and then written in any of the
+// feed formats.
+type syndicationEntry struct {
+	blog      types_blog.Blog
+	link      string
+	author    string
+	published time.Time
+	updated   time.Time
+	content   string
+	image     string
+	imageType string
+	imageSize int64
+}
+
+func blogURL(slug string) string {
+	return fmt.Sprintf("%s/blog/%s", config.Env.Host, url.PathEscape(slug))
+}
+
+func blogImageURL(name string) string {
+	return fmt.Sprintf("%s/api/v1/blog/image/%s", config.Env.Host, url.PathEscape(name))
+}
+
+func (h *Handler) getImage(w http.ResponseWriter, r *http.Request) {
+	name := filepath.Base(mux.Vars(r)["name"])
+
+	path := filepath.Join(h.imageUploadDir, name)
+	if exists, err := utils.PathExists(path); err != nil || !exists {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+func (h *Handler) getSyndicationFeed(w http.ResponseWriter, r *http.Request) {
+	format := mux.Vars(r)["format"]
+	params := r.URL.Query()
+
+	mode := params.Get("mode")
+	if mode == "" {
+		mode = "summary"
+	}
+
+	if mode != "summary" && mode != "full" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid feed mode")
+		return
+	}
+
+	query := types_blog.SearchBlogQuery{
+		Status:     types_blog.BlogStatusPublished,
+		PublicOnly: true,
+		SortBy:     "publishedAt",
+		SortOrder:  "desc",
+		Page:       1,
+		Limit:      syndicationFeedSize,
+	}
+
+	title := siteName
+
+	if username := params.Get("author"); username != "" {
+		u, err := h.userStore.GetUserByUsername(username)
+		if err != nil || u == nil {
+			utils.WriteErrorInResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+
+		query.AuthorId = u.Id
+		title = fmt.Sprintf("%s - %s", title, u.Username)
+	}
+
+	if tag := params.Get("tag"); tag != "" {
+		t, err := h.store.GetTagBySlug(utils.CreateSlug(tag))
+		if err != nil || t == nil {
+			utils.WriteErrorInResponse(w, http.StatusNotFound, "Tag not found")
+			return
+		}
+
+		query.Tag = t.Slug
+		title = fmt.Sprintf("%s - #%s", title, t.Name)
+	}
+
+	blogs, _, err := h.store.GetBlogs(query)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	entries := h.syndicationEntries(blogs)
+
+	lastModified := time.Time{}
+	fingerprint := sha256.New()
+	fmt.Fprintf(fingerprint, "%s|%s|%s|", format, mode, r.URL.RawQuery)
+
+	for _, e := range entries {
+		if e.updated.After(lastModified) {
+			lastModified = e.updated
+		}
+
+		fmt.Fprintf(fingerprint, "%s:%d|", e.blog.Id, e.updated.UnixNano())
+	}
+
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(fingerprint.Sum(nil))[:32])
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if mode == "full" {
+		for i := range entries {
+			if html, _, _, ok := h.renderContent(&entries[i].blog); ok {
+				entries[i].content = html
+			}
+		}
+	}
+
+	// The entries are ordered by the date they were published, and the feed is
+	// dated by the newest of them so both agree on what is recent.
+	updated := time.Time{}
+	if len(entries) > 0 {
+		updated = entries[0].published.UTC().Truncate(time.Second)
+	}
+
+	selfURL := fmt.Sprintf("%s%s", config.Env.Host, r.URL.RequestURI())
+	homeURL := fmt.Sprintf("%s/blog", config.Env.Host)
+
+	switch format {
+	case "rss":
+		writeRSS(w, entries, title, homeURL, selfURL, updated, mode == "full")
+	case "atom":
+		writeAtom(w, entries, title, homeURL, selfURL, updated, mode == "full")
+	default:
+		writeJSONFeed(w, entries, title, homeURL, selfURL, mode == "full")
+	}
+}
+
+func (h *Handler) syndicationEntries(blogs []types_blog.Blog) []syndicationEntry {
+	authors := map[string]string{}
+	entries := make([]syndicationEntry, 0, len(blogs))
+
+	for i := range blogs {
+		b := blogs[i]
+
+		e := syndicationEntry{
+			blog:      b,
+			link:      blogURL(b.Slug),
+			published: b.CreatedAt,
+			updated:   b.UpdatedAt,
+		}
+
+		if b.PublishedAt != nil {
+			e.published = *b.PublishedAt
+		}
+
+		if e.updated.Before(e.published) {
+			e.updated = e.published
+		}
+
+		if b.AuthorId != "" {
+			name, ok := authors[b.AuthorId]
+			if !ok {
+				if u, err := h.userStore.GetUserById(b.AuthorId); err == nil && u != nil {
+					name = strings.TrimSpace(fmt.Sprintf("%s %s", u.FirstName, u.LastName))
+					if name == "" {
+						name = u.Username
+					}
+				}
+
+				authors[b.AuthorId] = name
+			}
+
+			e.author = name
+		}
+
+		if b.PictureName != "" {
+			path := filepath.Join(h.imageUploadDir, filepath.Base(b.PictureName))
+			if info, err := os.Stat(path); err == nil {
+				e.image = blogImageURL(b.PictureName)
+				e.imageSize = info.Size()
+				e.imageType = mime.TypeByExtension(strings.ToLower(filepath.Ext(b.PictureName)))
+				if e.imageType == "" {
+					e.imageType = "application/octet-stream"
+				}
+			}
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// notModified follows RFC 9110: If-None-Match takes precedence and
+// If-Modified-Since is only consulted when it is absent.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+
+	return false
+}
+
+func writeRSS(
+	w http.ResponseWriter,
+	entries []syndicationEntry,
+	title string,
+	homeURL string,
+	selfURL string,
+	updated time.Time,
+	full bool,
+) {
+	feed := rssFeed{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        homeURL,
+			Description: fmt.Sprintf("Latest posts on %s", title),
+			AtomLink: atomLink{
+				Href: selfURL,
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Items: []rssItem{},
+		},
+	}
+
+	if !updated.IsZero() {
+		feed.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+
+	for _, e := range entries {
+		item := rssItem{
+			Title:       e.blog.Title,
+			Link:        e.link,
+			Guid:        rssGuid{IsPermaLink: true, Value: e.link},
+			PubDate:     e.published.UTC().Format(time.RFC1123Z),
+			Description: e.blog.Description,
+		}
+
+		for _, t := range e.blog.Tags {
+			item.Categories = append(item.Categories, t.Name)
+		}
+
+		if full && e.content != "" {
+			item.Content = &rssContent{Value: e.content}
+		}
+
+		if e.image != "" {
+			item.Enclosure = &rssEnclosure{URL: e.image, Length: e.imageSize, Type: e.imageType}
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	writeXMLFeed(w, "application/rss+xml; charset=utf-8", feed)
+}
+
+func writeAtom(
+	w http.ResponseWriter,
+	entries []syndicationEntry,
+	title string,
+	homeURL string,
+	selfURL string,
+	updated time.Time,
+	full bool,
+) {
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	feed := atomFeed{
+		Id:      selfURL,
+		Title:   title,
+		Updated: updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: homeURL, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: []atomEntry{},
+	}
+
+	for _, e := range entries {
+		entry := atomEntry{
+			Id:        e.link,
+			Title:     e.blog.Title,
+			Updated:   e.updated.UTC().Format(time.RFC3339),
+			Published: e.published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: e.link, Rel: "alternate", Type: "text/html"}},
+			Summary:   e.blog.Description,
+		}
+
+		if e.author != "" {
+			entry.Author = &atomAuthor{Name: e.author}
+		}
+
+		for _, t := range e.blog.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t.Slug, Label: t.Name})
+		}
+
+		if full && e.content != "" {
+			entry.Content = &atomContent{Type: "html", Value: e.content}
+		}
+
+		if e.image != "" {
+			entry.Links = append(entry.Links, atomLink{
+				Href:   e.image,
+				Rel:    "enclosure",
+				Type:   e.imageType,
+				Length: e.imageSize,
+			})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	writeXMLFeed(w, "application/atom+xml; charset=utf-8", feed)
+}
+
+func writeJSONFeed(
+	w http.ResponseWriter,
+	entries []syndicationEntry,
+	title string,
+	homeURL string,
+	selfURL string,
+	full bool,
+) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       title,
+		HomePageURL: homeURL,
+		FeedURL:     selfURL,
+		Items:       []jsonFeedItem{},
+	}
+
+	for _, e := range entries {
+		item := jsonFeedItem{
+			Id:            e.link,
+			URL:           e.link,
+			Title:         e.blog.Title,
+			Summary:       e.blog.Description,
+			DatePublished: e.published.UTC().Format(time.RFC3339),
+			DateModified:  e.updated.UTC().Format(time.RFC3339),
+			Image:         e.image,
+		}
+
+		if full && e.content != "" {
+			item.ContentHTML = e.content
+		} else {
+			item.ContentText = e.blog.Description
+		}
+
+		for _, t := range e.blog.Tags {
+			item.Tags = append(item.Tags, t.Name)
+		}
+
+		if e.author != "" {
+			item.Authors = []jsonFeedAuthor{{Name: e.author}}
+		}
+
+		if e.image != "" {
+			item.Attachments = []jsonFeedAttachment{
+				{URL: e.image, MimeType: e.imageType, Size: e.imageSize},
+			}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(feed)
+}
+
+func writeXMLFeed(w http.ResponseWriter, contentType string, feed any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	encoder.Encode(feed)
+}
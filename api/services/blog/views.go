@@ -0,0 +1,214 @@
+package blog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+)
+
+// maxPendingViews bounds the views kept in memory while the database can't be
+// reached. Views past it are dropped, the counters are only an estimate.
+const maxPendingViews = 100000
+
+// ViewCounter buffers blog views in memory and writes them in batches so that
+// reading a blog never waits on an UPDATE of its counter. Views are keyed by
+// blog, visitor and day, which de-duplicates repeated visits within a day.
+type ViewCounter struct {
+	store          types_blog.BlogStore
+	interval       time.Duration
+	trustedProxies []*net.IPNet
+	maxPending     int
+
+	mu      sync.Mutex
+	pending map[types_blog.BlogView]struct{}
+	dropped int
+}
+
+func NewViewCounter(
+	store types_blog.BlogStore,
+	interval time.Duration,
+	trustedProxies []*net.IPNet,
+) *ViewCounter {
+	return &ViewCounter{
+		store:          store,
+		interval:       interval,
+		trustedProxies: trustedProxies,
+		maxPending:     maxPendingViews,
+		pending:        map[types_blog.BlogView]struct{}{},
+	}
+}
+
+// ParseTrustedProxies parses a comma separated list of IP addresses and CIDR
+// ranges of the proxies whose X-Forwarded-For headers can be trusted.
+func ParseTrustedProxies(list string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid trusted proxy %q", entry)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy %q", entry)
+		}
+
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
+
+func (c *ViewCounter) Record(blogId string, visitorId string) {
+	view := types_blog.BlogView{
+		BlogId:    blogId,
+		VisitorId: visitorId,
+		Day:       time.Now().UTC().Format(time.DateOnly),
+	}
+
+	c.mu.Lock()
+	c.add(view)
+	c.mu.Unlock()
+}
+
+// add must be called with the lock held.
+func (c *ViewCounter) add(view types_blog.BlogView) {
+	if _, ok := c.pending[view]; ok {
+		return
+	}
+
+	if len(c.pending) >= c.maxPending {
+		c.dropped++
+		return
+	}
+
+	c.pending[view] = struct{}{}
+}
+
+func (c *ViewCounter) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.flush()
+			return
+		case <-ticker.C:
+			c.flush()
+		}
+	}
+}
+
+func (c *ViewCounter) flush() {
+	c.mu.Lock()
+	pending := c.pending
+	dropped := c.dropped
+	c.pending = map[types_blog.BlogView]struct{}{}
+	c.dropped = 0
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		log.Printf("dropped %d blog views, too many were waiting to be recorded", dropped)
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	views := make([]types_blog.BlogView, 0, len(pending))
+	for v := range pending {
+		views = append(views, v)
+	}
+
+	if _, err := c.store.RecordViews(views); err != nil {
+		log.Printf("failed to record %d blog views: %v", len(views), err)
+
+		c.mu.Lock()
+		for _, v := range views {
+			c.add(v)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *ViewCounter) visitorId(r *http.Request) string {
+	if userId, ok := r.Context().Value("userId").(string); ok && userId != "" {
+		return userId
+	}
+
+	hash := sha256.Sum256([]byte(c.clientIP(r) + "|" + r.UserAgent()))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// clientIP only reads X-Forwarded-For when the request comes from a trusted
+// proxy, since anyone else can put whatever they like in it. The addresses are
+// read from the right, and the first one that isn't a trusted proxy is the
+// client.
+func (c *ViewCounter) clientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	if !c.trusted(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		ip = hop
+		if !c.trusted(hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (c *ViewCounter) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range c.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
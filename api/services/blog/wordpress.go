@@ -0,0 +1,181 @@
+package blog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+var (
+	wxrCaptionRegex = regexp.MustCompile(`\[/?caption[^\]]*\]`)
+	wxrResizedRegex = regexp.MustCompile(`-\d+x\d+(\.\w+)$`)
+)
+
+// The WordPress export namespaces carry a version, so elements are matched by
+// their local name and the excerpt is told apart from the content by its
+// namespace.
+type wxrExport struct {
+	Items []wxrItem `xml:"channel>item"`
+}
+
+type wxrItem struct {
+	Title         string        `xml:"title"`
+	PubDate       string        `xml:"pubDate"`
+	Encoded       []wxrEncoded  `xml:"encoded"`
+	PostId        string        `xml:"post_id"`
+	PostDate      string        `xml:"post_date"`
+	PostDateGMT   string        `xml:"post_date_gmt"`
+	PostName      string        `xml:"post_name"`
+	Status        string        `xml:"status"`
+	PostType      string        `xml:"post_type"`
+	AttachmentURL string        `xml:"attachment_url"`
+	Categories    []wxrCategory `xml:"category"`
+	Meta          []wxrMeta     `xml:"postmeta"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+type wxrMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+// ImportWXR imports the posts of a WordPress export. Their HTML is converted
+// to markdown, and images under wp-content/uploads are read from uploadsDir,
+// which mirrors that directory. Images that can't be found there keep
+// pointing at the old site.
+func (i *Importer) ImportWXR(
+	r io.Reader,
+	uploadsDir string,
+	now time.Time,
+) (*types_blog.ImportReport, error) {
+	var export wxrExport
+	if err := xml.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("Invalid WordPress export: %v", err)
+	}
+
+	attachments := map[string]string{}
+	for _, item := range export.Items {
+		if item.PostType == "attachment" && item.AttachmentURL != "" {
+			attachments[item.PostId] = item.AttachmentURL
+		}
+	}
+
+	report := &types_blog.ImportReport{
+		Format: types_blog.ImportFormatWordPress,
+		DryRun: i.dryRun,
+		Posts:  []types_blog.ImportPost{},
+	}
+
+	for _, item := range export.Items {
+		if item.PostType != "post" {
+			continue
+		}
+
+		post := importSource{
+			source:  fmt.Sprintf("post %s", item.PostId),
+			title:   strings.TrimSpace(item.Title),
+			date:    wxrDate(item),
+			draft:   item.Status != "publish" && item.Status != "future",
+			resolve: wxrResolver(uploadsDir),
+		}
+
+		post.slug, _ = url.PathUnescape(item.PostName)
+		if post.slug == "" {
+			post.slug = post.title
+		}
+
+		for _, e := range item.Encoded {
+			text := wxrCaptionRegex.ReplaceAllString(e.Value, "")
+			if strings.Contains(e.XMLName.Space, "excerpt") {
+				post.description = strings.TrimSpace(utils.HTMLToMarkdown(text))
+			} else {
+				post.content = utils.HTMLToMarkdown(text)
+			}
+		}
+
+		for _, c := range item.Categories {
+			if c.Domain == "post_tag" && strings.TrimSpace(c.Name) != "" {
+				post.tags = append(post.tags, strings.TrimSpace(c.Name))
+			}
+		}
+
+		for _, m := range item.Meta {
+			if m.Key == "_thumbnail_id" {
+				post.cover = attachments[m.Value]
+			}
+		}
+
+		addImportPost(report, i.importPost(post, now))
+	}
+
+	return report, nil
+}
+
+// wxrDate prefers the GMT date, which drafts leave zeroed, over the date in
+// the site's time zone.
+func wxrDate(item wxrItem) *time.Time {
+	if t, err := time.Parse(wxrDateLayout, item.PostDateGMT); err == nil {
+		return &t
+	}
+
+	if t, err := time.Parse(time.RFC1123Z, item.PubDate); err == nil {
+		return &t
+	}
+
+	if t, err := time.Parse(wxrDateLayout, item.PostDate); err == nil {
+		return &t
+	}
+
+	return nil
+}
+
+func wxrResolver(uploadsDir string) func(ref string) (string, error) {
+	return func(ref string) (string, error) {
+		u, err := url.Parse(ref)
+		if err != nil || uploadsDir == "" {
+			return "", nil
+		}
+
+		_, rel, ok := strings.Cut(u.Path, "/wp-content/uploads/")
+		if !ok {
+			return "", nil
+		}
+
+		rel = path.Clean("/" + rel)
+
+		candidates := []string{rel}
+		if resized := wxrResizedRegex.ReplaceAllString(rel, "$1"); resized != rel {
+			candidates = append(candidates, resized)
+		}
+
+		for _, c := range candidates {
+			p := filepath.Join(uploadsDir, filepath.FromSlash(c))
+			if info, err := os.Stat(p); err == nil && !info.IsDir() {
+				return p, nil
+			}
+		}
+
+		return "", fmt.Errorf("Image %s was not found in the uploads", ref)
+	}
+}
@@ -0,0 +1,86 @@
+package comment
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	codeSpanRegex    = regexp.MustCompile("`([^`\n]+)`")
+	linkRegex        = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	boldRegex        = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	italicRegex      = regexp.MustCompile(`\*([^*\n]+)\*`)
+	placeholderRegex = regexp.MustCompile("\x00([0-9]+)\x00")
+	paragraphRegex   = regexp.MustCompile(`\n\s*\n`)
+)
+
+// renderMarkdownLite renders the small markdown subset allowed in comments:
+// paragraphs, line breaks, bold, italic, inline code and http(s)/mailto links.
+// The input is escaped before any markup is added, so raw HTML never survives.
+func renderMarkdownLite(body string) string {
+	body = strings.ReplaceAll(strings.TrimSpace(body), "\r\n", "\n")
+	if body == "" {
+		return ""
+	}
+
+	paragraphs := []string{}
+	for _, p := range paragraphRegex.Split(body, -1) {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		lines := strings.Split(p, "\n")
+		for i := range lines {
+			lines[i] = renderInline(lines[i])
+		}
+
+		paragraphs = append(paragraphs, fmt.Sprintf("<p>%s</p>", strings.Join(lines, "<br>")))
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+func renderInline(line string) string {
+	protected := []string{}
+	protect := func(s string) string {
+		protected = append(protected, s)
+		return fmt.Sprintf("\x00%d\x00", len(protected)-1)
+	}
+
+	line = strings.ReplaceAll(line, "\x00", "")
+
+	line = codeSpanRegex.ReplaceAllStringFunc(line, func(m string) string {
+		code := codeSpanRegex.FindStringSubmatch(m)[1]
+		return protect(fmt.Sprintf("<code>%s</code>", html.EscapeString(code)))
+	})
+
+	line = linkRegex.ReplaceAllStringFunc(line, func(m string) string {
+		parts := linkRegex.FindStringSubmatch(m)
+		text := html.EscapeString(parts[1])
+
+		u, err := url.Parse(parts[2])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+			return protect(text)
+		}
+
+		return protect(fmt.Sprintf(
+			"<a href=\"%s\" rel=\"nofollow noopener noreferrer\">%s</a>",
+			html.EscapeString(u.String()),
+			text,
+		))
+	})
+
+	line = html.EscapeString(line)
+	line = boldRegex.ReplaceAllString(line, "<strong>$1</strong>")
+	line = italicRegex.ReplaceAllString(line, "<em>$1</em>")
+
+	return placeholderRegex.ReplaceAllStringFunc(line, func(m string) string {
+		i, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(m)[1])
+		return protected[i]
+	})
+}
@@ -0,0 +1,37 @@
+package comment
+
+import (
+	"testing"
+)
+
+func TestRenderMarkdownLite(t *testing.T) {
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{
+			body: "**bold** and *italic* with `<code>`",
+			expected: "<p><strong>bold</strong> and <em>italic</em> with " +
+				"<code>&lt;code&gt;</code></p>",
+		},
+		{
+			body:     "first line\nsecond line\n\nnew paragraph",
+			expected: "<p>first line<br>second line</p>\n<p>new paragraph</p>",
+		},
+		{
+			body: "see [the docs](https://example.com/a?b=1&c=2)",
+			expected: "<p>see <a href=\"https://example.com/a?b=1&amp;c=2\" " +
+				"rel=\"nofollow noopener noreferrer\">the docs</a></p>",
+		},
+		{
+			body:     "[click](javascript:void) <script>alert(1)</script>",
+			expected: "<p>click &lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		},
+	}
+
+	for _, c := range cases {
+		if html := renderMarkdownLite(c.body); html != c.expected {
+			t.Errorf("Expected %q, received %q", c.expected, html)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package comment
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/services/blog"
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/types/comment"
+	"github.com/SaeedAlian/megavault/api/types/user"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+type Handler struct {
+	store     types_comment.CommentStore
+	blogStore types_blog.BlogStore
+	userStore types_user.UserStore
+}
+
+func NewHandler(
+	store types_comment.CommentStore,
+	blogStore types_blog.BlogStore,
+	userStore types_user.UserStore,
+) *Handler {
+	return &Handler{
+		store:     store,
+		blogStore: blogStore,
+		userStore: userStore,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/moderation", auth.WithJWTAuth(h.getModerationQueue, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/blog/{blogId}", auth.WithOptionalJWTAuth(h.getComments, h.userStore)).
+		Methods("GET")
+	router.HandleFunc("/blog/{blogId}", auth.WithJWTAuth(h.createComment, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/blog/{blogId}/settings", auth.WithJWTAuth(h.updateSettings, h.userStore)).
+		Methods("PATCH")
+	router.HandleFunc("/{id}/approve", auth.WithJWTAuth(h.approveComment, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/{id}/reject", auth.WithJWTAuth(h.rejectComment, h.userStore)).
+		Methods("POST")
+	router.HandleFunc("/{id}", auth.WithJWTAuth(h.updateComment, h.userStore)).Methods("PATCH")
+	router.HandleFunc("/{id}", auth.WithJWTAuth(h.deleteComment, h.userStore)).Methods("DELETE")
+}
+
+func (h *Handler) getComments(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.getReadableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+
+	comments, err := h.store.GetCommentsByBlogId(b.Id, userId)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	payload := map[string][]types_comment.Comment{
+		"result": buildCommentTree(comments, ""),
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) createComment(w http.ResponseWriter, r *http.Request) {
+	var payload types_comment.CreateCommentPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid comment payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	b, ok := h.getReadableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	enabled, err := h.store.GetCommentsEnabled(b.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	if !enabled {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusForbidden,
+			"Comments are disabled for this blog",
+		)
+		return
+	}
+
+	if payload.ParentId != "" {
+		parent, err := h.store.GetCommentById(payload.ParentId)
+		if err != nil || parent == nil || parent.BlogId != b.Id ||
+			parent.Status != types_comment.CommentStatusApproved || parent.DeletedAt != nil {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"The comment you are replying to doesn't exist",
+			)
+			return
+		}
+	}
+
+	userId := r.Context().Value("userId").(string)
+
+	status := types_comment.CommentStatusApproved
+	if !auth.IsAdmin(r, h.userStore) {
+		approved, err := h.store.CountApprovedCommentsByAuthor(userId)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+			return
+		}
+
+		if approved == 0 {
+			status = types_comment.CommentStatusPending
+		}
+	}
+
+	c, err := h.store.CreateComment(types_comment.CreateCommentPayload{
+		Body:     payload.Body,
+		ParentId: payload.ParentId,
+		BlogId:   b.Id,
+		AuthorId: userId,
+		Status:   status,
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	c.BodyHTML = renderMarkdownLite(c.Body)
+
+	utils.WriteJSONInResponse(w, http.StatusCreated, c, nil)
+}
+
+func (h *Handler) updateComment(w http.ResponseWriter, r *http.Request) {
+	var payload types_comment.UpdateCommentPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid comment payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	c, ok := h.getCommentFromVars(w, r)
+	if !ok {
+		return
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	if c.AuthorId != userId {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusForbidden,
+			"You can only edit your own comments",
+		)
+		return
+	}
+
+	editWindow := time.Duration(config.Env.CommentEditWindowMinutes) * time.Minute
+	if time.Since(c.CreatedAt) > editWindow {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusForbidden,
+			"The time to edit this comment has passed",
+		)
+		return
+	}
+
+	if err := h.store.UpdateComment(c.Id, payload.Body, time.Now()); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Comment with id %s has been updated successfully", c.Id),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) deleteComment(w http.ResponseWriter, r *http.Request) {
+	c, ok := h.getCommentFromVars(w, r)
+	if !ok {
+		return
+	}
+
+	userId, _ := r.Context().Value("userId").(string)
+	if c.AuthorId != userId && !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusForbidden,
+			"You can only delete your own comments",
+		)
+		return
+	}
+
+	if err := h.store.DeleteComment(c.Id, time.Now()); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Comment with id %s has been deleted successfully", c.Id),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	var payload types_comment.CommentSettingsPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid settings payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	b, err := h.blogStore.GetBlogById(mux.Vars(r)["blogId"])
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	if !blog.CanEdit(w, r, b, h.userStore) {
+		return
+	}
+
+	if err := h.store.SetCommentsEnabled(b.Id, *payload.Enabled); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]bool{"enabled": *payload.Enabled},
+		nil,
+	)
+}
+
+func (h *Handler) getModerationQueue(w http.ResponseWriter, r *http.Request) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can moderate comments")
+		return
+	}
+
+	comments, err := h.store.GetPendingComments()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	for i := range comments {
+		comments[i].BodyHTML = renderMarkdownLite(comments[i].Body)
+	}
+
+	payload := map[string][]types_comment.Comment{
+		"result": comments,
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
+}
+
+func (h *Handler) approveComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, types_comment.CommentStatusApproved)
+}
+
+func (h *Handler) rejectComment(w http.ResponseWriter, r *http.Request) {
+	h.moderateComment(w, r, types_comment.CommentStatusRejected)
+}
+
+func (h *Handler) moderateComment(w http.ResponseWriter, r *http.Request, status string) {
+	if !auth.IsAdmin(r, h.userStore) {
+		utils.WriteErrorInResponse(w, http.StatusForbidden, "Only admins can moderate comments")
+		return
+	}
+
+	c, ok := h.getCommentFromVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.UpdateCommentStatus(c.Id, status); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Comment with id %s has been %s", c.Id, status),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) getReadableBlog(w http.ResponseWriter, r *http.Request) (*types_blog.Blog, bool) {
+	b, err := h.blogStore.GetBlogById(mux.Vars(r)["blogId"])
+	if err != nil || b == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Blog not found")
+		return nil, false
+	}
+
+	if !blog.CanRead(w, r, b) {
+		return nil, false
+	}
+
+	return b, true
+}
+
+func (h *Handler) getCommentFromVars(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*types_comment.Comment, bool) {
+	c, err := h.store.GetCommentById(mux.Vars(r)["id"])
+	if err != nil || c == nil || c.DeletedAt != nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Comment not found")
+		return nil, false
+	}
+
+	return c, true
+}
+
+func buildCommentTree(comments []types_comment.Comment, parentId string) []types_comment.Comment {
+	tree := []types_comment.Comment{}
+
+	for _, c := range comments {
+		if c.ParentId != parentId {
+			continue
+		}
+
+		c.Replies = buildCommentTree(comments, c.Id)
+
+		if c.DeletedAt != nil {
+			if len(c.Replies) == 0 {
+				continue
+			}
+
+			c.Body = ""
+			c.AuthorId = ""
+		}
+
+		c.BodyHTML = renderMarkdownLite(c.Body)
+		tree = append(tree, c)
+	}
+
+	return tree
+}
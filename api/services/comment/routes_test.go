@@ -0,0 +1,896 @@
+package comment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/SaeedAlian/megavault/api/types/blog"
+	"github.com/SaeedAlian/megavault/api/types/comment"
+	"github.com/SaeedAlian/megavault/api/types/user"
+)
+
+func TestCommentService(t *testing.T) {
+	userStore := MockUserStore{
+		DefaultUsers: []types_user.User{
+			{Id: "99", Username: "admin", Role: types_user.UserRoleAdmin},
+		},
+	}
+	blogStore := MockBlogStore{
+		DefaultBlogs: []types_blog.Blog{
+			{
+				Id:         "1",
+				Title:      "Blog1",
+				Slug:       "blog1",
+				AuthorId:   "20",
+				Visibility: types_blog.BlogVisibilityPublic,
+				Status:     types_blog.BlogStatusPublished,
+			},
+			{
+				Id:         "2",
+				Title:      "Blog2",
+				Slug:       "blog2",
+				AuthorId:   "20",
+				Visibility: types_blog.BlogVisibilityPublic,
+				Status:     types_blog.BlogStatusPublished,
+			},
+			{
+				Id:       "3",
+				Title:    "Blog3",
+				Slug:     "blog3",
+				AuthorId: "20",
+				Authors: []types_blog.BlogAuthor{
+					{UserId: "20", Role: types_blog.BlogAuthorRoleOwner},
+					{UserId: "30", Role: types_blog.BlogAuthorRoleCoAuthor},
+				},
+				Visibility: types_blog.BlogVisibilityPublic,
+				Status:     types_blog.BlogStatusDraft,
+			},
+		},
+	}
+	commentStore := MockCommentStore{
+		DefaultComments: []types_comment.Comment{
+			{
+				Id:        "00000000-0000-4000-8000-000000000001",
+				BlogId:    "1",
+				AuthorId:  "10",
+				Body:      "First **comment**",
+				Status:    types_comment.CommentStatusApproved,
+				CreatedAt: time.Now(),
+			},
+			{
+				Id:        "00000000-0000-4000-8000-000000000002",
+				BlogId:    "1",
+				AuthorId:  "11",
+				Body:      "An old comment",
+				Status:    types_comment.CommentStatusApproved,
+				CreatedAt: time.Now().Add(-time.Hour),
+			},
+		},
+		Disabled: map[string]bool{"2": true},
+	}
+
+	handler := NewHandler(&commentStore, &blogStore, &userStore)
+
+	t.Run("should hold the comment of a first-time commenter", func(t *testing.T) {
+		c := createComment(t, handler, "1", "12", types_comment.CreateCommentPayload{
+			Body: "Hello there",
+		}, http.StatusCreated)
+
+		if c.Status != types_comment.CommentStatusPending {
+			t.Errorf("Expected status pending, received %s", c.Status)
+		}
+	})
+
+	t.Run("should approve the reply of a returning commenter", func(t *testing.T) {
+		c := createComment(t, handler, "1", "10", types_comment.CreateCommentPayload{
+			Body:     "A *reply*",
+			ParentId: "00000000-0000-4000-8000-000000000002",
+		}, http.StatusCreated)
+
+		if c.Status != types_comment.CommentStatusApproved {
+			t.Errorf("Expected status approved, received %s", c.Status)
+		}
+
+		if c.BodyHTML != "<p>A <em>reply</em></p>" {
+			t.Errorf("Unexpected rendered body %q", c.BodyHTML)
+		}
+	})
+
+	t.Run("should fail to reply to a missing comment", func(t *testing.T) {
+		createComment(t, handler, "1", "10", types_comment.CreateCommentPayload{
+			Body:     "A reply",
+			ParentId: "00000000-0000-4000-8000-000000000099",
+		}, http.StatusBadRequest)
+	})
+
+	t.Run("should fail to comment when comments are disabled", func(t *testing.T) {
+		createComment(t, handler, "2", "10", types_comment.CreateCommentPayload{
+			Body: "Hello",
+		}, http.StatusForbidden)
+	})
+
+	t.Run("should list approved comments as a tree", func(t *testing.T) {
+		comments := getComments(t, handler, "1")
+
+		if len(comments) != 2 {
+			t.Fatalf("Expected 2 top level comments, received %d", len(comments))
+		}
+
+		for _, c := range comments {
+			if c.Status != types_comment.CommentStatusApproved {
+				t.Errorf("Expected only approved comments, received %s", c.Status)
+			}
+
+			if c.Id == "00000000-0000-4000-8000-000000000002" && len(c.Replies) != 1 {
+				t.Errorf("Expected 1 reply, received %d", len(c.Replies))
+			}
+		}
+	})
+
+	t.Run("should fail to edit a comment after the edit window", func(t *testing.T) {
+		rr := editComment(handler, "00000000-0000-4000-8000-000000000002", "11", "Edited")
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should fail to edit the comment of someone else", func(t *testing.T) {
+		rr := editComment(handler, "00000000-0000-4000-8000-000000000001", "11", "Edited")
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should edit a comment within the edit window", func(t *testing.T) {
+		rr := editComment(handler, "00000000-0000-4000-8000-000000000001", "10", "Edited")
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		c, _ := commentStore.GetCommentById("00000000-0000-4000-8000-000000000001")
+		if c.Body != "Edited" || c.EditedAt == nil {
+			t.Errorf("Expected the comment to be edited, received %v", c)
+		}
+	})
+
+	t.Run("should soft delete a comment and keep its replies", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/comment/00000000-0000-4000-8000-000000000002", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "11"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/comment/{id}", handler.deleteComment).Methods("DELETE")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		for _, c := range getComments(t, handler, "1") {
+			if c.Id != "00000000-0000-4000-8000-000000000002" {
+				continue
+			}
+
+			if c.Body != "" || len(c.Replies) != 1 {
+				t.Errorf("Expected a deleted placeholder with its reply, received %v", c)
+			}
+		}
+	})
+
+	t.Run("should fail to see the moderation queue without being an admin", func(t *testing.T) {
+		rr := getModerationQueue(handler, "10")
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("Expected code %d, received %d", http.StatusForbidden, rr.Code)
+		}
+	})
+
+	t.Run("should approve a held comment as an admin", func(t *testing.T) {
+		rr := getModerationQueue(handler, "99")
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		var res map[string][]types_comment.Comment
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res["result"]) != 1 {
+			t.Fatalf("Expected 1 held comment, received %d", len(res["result"]))
+		}
+
+		id := res["result"][0].Id
+
+		req, err := http.NewRequest("POST", fmt.Sprintf("/comment/%s/approve", id), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "99"))
+
+		rr = httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/comment/{id}/approve", handler.approveComment).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		c, _ := commentStore.GetCommentById(id)
+		if c.Status != types_comment.CommentStatusApproved {
+			t.Errorf("Expected status approved, received %s", c.Status)
+		}
+	})
+
+	t.Run("should disable comments as the author of the blog", func(t *testing.T) {
+		marshalled, err := json.Marshal(map[string]bool{"enabled": false})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(
+			"PATCH",
+			"/comment/blog/1/settings",
+			bytes.NewBuffer(marshalled),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "20"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/comment/blog/{blogId}/settings", handler.updateSettings).
+			Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if enabled, _ := commentStore.GetCommentsEnabled("1"); enabled {
+			t.Errorf("Expected comments to be disabled")
+		}
+	})
+
+	t.Run("should manage the comments of a draft as a co-author", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/comment/blog/3", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "30"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/comment/blog/{blogId}", handler.getComments).Methods("GET")
+		router.HandleFunc("/comment/blog/{blogId}/settings", handler.updateSettings).
+			Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		req, err = http.NewRequest(
+			"PATCH",
+			"/comment/blog/3/settings",
+			bytes.NewBufferString(`{"enabled":false}`),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "30"))
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if enabled, _ := commentStore.GetCommentsEnabled("3"); enabled {
+			t.Errorf("Expected comments to be disabled")
+		}
+	})
+}
+
+func createComment(
+	t *testing.T,
+	handler *Handler,
+	blogId string,
+	userId string,
+	payload types_comment.CreateCommentPayload,
+	expectedCode int,
+) *types_comment.Comment {
+	marshalled, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("/comment/blog/%s", blogId),
+		bytes.NewBuffer(marshalled),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+
+	router.HandleFunc("/comment/blog/{blogId}", handler.createComment).Methods("POST")
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != expectedCode {
+		t.Fatalf("Expected code %d, received %d", expectedCode, rr.Code)
+	}
+
+	var c types_comment.Comment
+	json.NewDecoder(rr.Body).Decode(&c)
+
+	return &c
+}
+
+func getComments(t *testing.T, handler *Handler, blogId string) []types_comment.Comment {
+	req, err := http.NewRequest("GET", fmt.Sprintf("/comment/blog/%s", blogId), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+
+	router.HandleFunc("/comment/blog/{blogId}", handler.getComments).Methods("GET")
+
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+	}
+
+	var res map[string][]types_comment.Comment
+	if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res["result"]
+}
+
+func editComment(
+	handler *Handler,
+	id string,
+	userId string,
+	body string,
+) *httptest.ResponseRecorder {
+	marshalled, _ := json.Marshal(types_comment.UpdateCommentPayload{Body: body})
+
+	req, _ := http.NewRequest("PATCH", fmt.Sprintf("/comment/%s", id), bytes.NewBuffer(marshalled))
+	req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+
+	router.HandleFunc("/comment/{id}", handler.updateComment).Methods("PATCH")
+
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func getModerationQueue(handler *Handler, userId string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/comment/moderation", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "userId", userId))
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+
+	router.HandleFunc("/comment/moderation", handler.getModerationQueue).Methods("GET")
+
+	router.ServeHTTP(rr, req)
+
+	return rr
+}
+
+type MockCommentStore struct {
+	DefaultComments []types_comment.Comment
+	Disabled        map[string]bool
+}
+
+type MockBlogStore struct {
+	DefaultBlogs []types_blog.Blog
+}
+
+type MockUserStore struct {
+	DefaultUsers []types_user.User
+}
+
+func (m *MockUserStore) GetUserById(id string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Id == id {
+			return &u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByUsername(username string) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByEmail(email string) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUserByUsernameOrEmail(
+	username string,
+	email string,
+) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) CreateUser(u types_user.RegisterUserPayload) (*types_user.User, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetUsers(
+	query types_user.SearchUserQuery,
+) ([]types_user.User, int, error) {
+	return nil, 0, nil
+}
+
+func (m *MockUserStore) DeleteUserById(
+	id string,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) DeleteUserByUsername(
+	username string,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) CreateEmailChange(
+	change types_user.CreateEmailChangePayload,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetEmailChangeByTokenHash(
+	tokenHash string,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetEmailChangeByUndoTokenHash(
+	undoTokenHash string,
+) (*types_user.EmailChange, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) ConfirmEmailChange(id string) error {
+	return nil
+}
+
+func (m *MockUserStore) RevertEmailChange(id string) error {
+	return nil
+}
+
+func (m *MockUserStore) ChangeUsername(id string, username string) error {
+	return nil
+}
+
+func (m *MockUserStore) GetUsernameHistory(
+	userId string,
+) ([]types_user.UsernameHistory, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetLatestUsernameHistoryByUsername(
+	username string,
+) (*types_user.UsernameHistory, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) GetPreferences(userId string) (*types_user.Preferences, error) {
+	return nil, nil
+}
+
+func (m *MockUserStore) UpdatePreferences(
+	userId string,
+	preferences types_user.Preferences,
+) error {
+	return nil
+}
+
+func (m *MockUserStore) FollowUser(followerId string, followeeId string) error {
+	return nil
+}
+
+func (m *MockUserStore) UnfollowUser(followerId string, followeeId string) error {
+	return nil
+}
+
+func (m *MockUserStore) GetFollowCounts(userId string) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (m *MockCommentStore) CreateComment(
+	payload types_comment.CreateCommentPayload,
+) (*types_comment.Comment, error) {
+	created := types_comment.Comment{
+		Id:        fmt.Sprintf("00000000-0000-4000-8000-%012d", rand.Int63n(1e12)),
+		BlogId:    payload.BlogId,
+		ParentId:  payload.ParentId,
+		AuthorId:  payload.AuthorId,
+		Body:      payload.Body,
+		Status:    payload.Status,
+		CreatedAt: time.Now(),
+	}
+
+	m.DefaultComments = append(m.DefaultComments, created)
+
+	return &created, nil
+}
+
+func (m *MockCommentStore) GetCommentById(id string) (*types_comment.Comment, error) {
+	for i := range m.DefaultComments {
+		c := m.DefaultComments[i]
+
+		if c.Id == id {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find comment")
+}
+
+func (m *MockCommentStore) GetCommentsByBlogId(
+	blogId string,
+	includeUnapprovedOf string,
+) ([]types_comment.Comment, error) {
+	res := []types_comment.Comment{}
+
+	for _, c := range m.DefaultComments {
+		if c.BlogId != blogId {
+			continue
+		}
+
+		if c.Status == types_comment.CommentStatusApproved ||
+			(includeUnapprovedOf != "" && c.AuthorId == includeUnapprovedOf) {
+			res = append(res, c)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *MockCommentStore) GetPendingComments() ([]types_comment.Comment, error) {
+	res := []types_comment.Comment{}
+
+	for _, c := range m.DefaultComments {
+		if c.Status == types_comment.CommentStatusPending && c.DeletedAt == nil {
+			res = append(res, c)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *MockCommentStore) UpdateComment(id string, body string, editedAt time.Time) error {
+	for i := range m.DefaultComments {
+		c := &m.DefaultComments[i]
+
+		if c.Id == id {
+			c.Body = body
+			c.EditedAt = &editedAt
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Comment not found to update")
+}
+
+func (m *MockCommentStore) DeleteComment(id string, deletedAt time.Time) error {
+	for i := range m.DefaultComments {
+		c := &m.DefaultComments[i]
+
+		if c.Id == id {
+			c.Body = ""
+			c.DeletedAt = &deletedAt
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Comment not found to delete")
+}
+
+func (m *MockCommentStore) UpdateCommentStatus(id string, status string) error {
+	for i := range m.DefaultComments {
+		c := &m.DefaultComments[i]
+
+		if c.Id == id {
+			c.Status = status
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Comment not found to update")
+}
+
+func (m *MockCommentStore) CountApprovedCommentsByAuthor(authorId string) (int, error) {
+	count := 0
+
+	for _, c := range m.DefaultComments {
+		if c.AuthorId == authorId && c.Status == types_comment.CommentStatusApproved {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *MockCommentStore) GetCommentsEnabled(blogId string) (bool, error) {
+	return !m.Disabled[blogId], nil
+}
+
+func (m *MockCommentStore) SetCommentsEnabled(blogId string, enabled bool) error {
+	m.Disabled[blogId] = !enabled
+
+	return nil
+}
+
+func (m *MockBlogStore) GetBlogById(id string) (*types_blog.Blog, error) {
+	for i := range m.DefaultBlogs {
+		b := m.DefaultBlogs[i]
+
+		if b.Id == id {
+			return &b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find blog")
+}
+
+func (m *MockBlogStore) CreateBlog(blog types_blog.CreateBlogPayload) (*types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetBlogs(query types_blog.SearchBlogQuery) ([]types_blog.Blog, int, error) {
+	return nil, 0, nil
+}
+
+func (m *MockBlogStore) GetBlogBySlug(slug string) (*types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetBlogByOldSlug(slug string) (*types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetBlogContent(id string) (*string, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) UpdateBlog(id string, blog types_blog.UpdateBlogPayload) error {
+	return nil
+}
+
+func (m *MockBlogStore) DeleteBlogById(id string) error {
+	return nil
+}
+
+func (m *MockBlogStore) GetFeed(query types_blog.FeedQuery) ([]types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) UpdateBlogStatus(
+	id string,
+	status types_blog.UpdateBlogStatusPayload,
+) error {
+	return nil
+}
+
+func (m *MockBlogStore) PublishScheduledBlogs(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockBlogStore) UnpublishExpiredBlogs(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockBlogStore) GetBlogRevisions(blogId string) ([]types_blog.BlogRevision, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetBlogRevision(
+	blogId string,
+	revision int,
+) (*types_blog.BlogRevision, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetTags() ([]types_blog.Tag, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetTagBySlug(slug string) (*types_blog.Tag, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) RenameTag(id string, name string, slug string) error {
+	return nil
+}
+
+func (m *MockBlogStore) MergeTags(sourceId string, targetId string) error {
+	return nil
+}
+
+func (m *MockBlogStore) GetCategories() ([]types_blog.Category, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetCategoryById(id string) (*types_blog.Category, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetCategoryBySlug(slug string) (*types_blog.Category, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) CreateCategory(
+	category types_blog.CreateCategoryPayload,
+) (*types_blog.Category, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) ToggleReaction(
+	blogId string,
+	userId string,
+	reaction string,
+) (bool, error) {
+	return false, nil
+}
+
+func (m *MockBlogStore) GetReactionCounts(blogId string) (map[string]int, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) RecordViews(views []types_blog.BlogView) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockBlogStore) GetBlogFiles() ([]types_blog.BlogFiles, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetRevisionFiles() ([]types_blog.BlogFiles, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetContentImageNames() ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) CountSitemapEntries() (int, error) {
+	return 0, nil
+}
+
+func (m *MockBlogStore) GetSitemapEntries(
+	offset int,
+	limit int,
+) ([]types_blog.SitemapEntry, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetRelatedBlogs(blogId string, limit int) ([]types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetSeries() ([]types_blog.Series, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetSeriesById(id string) (*types_blog.Series, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetSeriesBySlug(slug string) (*types_blog.Series, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetSeriesByBlogId(blogId string) (*types_blog.Series, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetSeriesParts(seriesId string) ([]types_blog.Blog, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) CreateSeries(
+	series types_blog.CreateSeriesPayload,
+) (*types_blog.Series, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) UpdateSeries(id string, series types_blog.UpdateSeriesPayload) error {
+	return nil
+}
+
+func (m *MockBlogStore) DeleteSeries(id string) error {
+	return nil
+}
+
+func (m *MockBlogStore) AddSeriesPart(seriesId string, blogId string, position int) error {
+	return nil
+}
+
+func (m *MockBlogStore) RemoveSeriesPart(seriesId string, blogId string) error {
+	return nil
+}
+
+func (m *MockBlogStore) ReorderSeries(seriesId string, blogIds []string) error {
+	return nil
+}
+
+func (m *MockBlogStore) CreateBlogInvitation(
+	invitation types_blog.InviteBlogAuthorPayload,
+) (*types_blog.BlogInvitation, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetBlogInvitationById(id string) (*types_blog.BlogInvitation, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) GetPendingBlogInvitations(
+	userId string,
+) ([]types_blog.BlogInvitation, error) {
+	return nil, nil
+}
+
+func (m *MockBlogStore) RespondToBlogInvitation(id string, accepted bool) error {
+	return nil
+}
+
+func (m *MockBlogStore) RemoveBlogAuthor(blogId string, userId string) error {
+	return nil
+}
+
+func (m *MockBlogStore) UpdateBlogStats(id string, stats types_blog.ReadingStats) error {
+	return nil
+}
@@ -0,0 +1,214 @@
+package comment
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/types/comment"
+)
+
+const commentColumns = "id, blogId, parentId, authorId, body, status, createdAt, editedAt, deletedAt"
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) CreateComment(
+	comment types_comment.CreateCommentPayload,
+) (*types_comment.Comment, error) {
+	rowId := ""
+	err := s.db.QueryRow(
+		"INSERT INTO comments (blogId,parentId,authorId,body,status) VALUES ($1,NULLIF($2, '')::UUID,$3,$4,$5) RETURNING id;",
+		comment.BlogId,
+		comment.ParentId,
+		comment.AuthorId,
+		comment.Body,
+		comment.Status,
+	).Scan(&rowId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCommentById(rowId)
+}
+
+func (s *Store) GetCommentById(id string) (*types_comment.Comment, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf("SELECT %s FROM comments WHERE id = $1;", commentColumns),
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		return scanRow(rows)
+	}
+
+	return nil, fmt.Errorf("Comment not found")
+}
+
+func (s *Store) GetCommentsByBlogId(
+	blogId string,
+	includeUnapprovedOf string,
+) ([]types_comment.Comment, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT %s FROM comments WHERE blogId = $1 AND (status = 'approved' OR authorId = NULLIF($2, '')::UUID) ORDER BY createdAt ASC, id ASC;",
+			commentColumns,
+		),
+		blogId,
+		includeUnapprovedOf,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+func (s *Store) GetPendingComments() ([]types_comment.Comment, error) {
+	rows, err := s.db.Query(
+		fmt.Sprintf(
+			"SELECT %s FROM comments WHERE status = 'pending' AND deletedAt IS NULL ORDER BY createdAt ASC, id ASC;",
+			commentColumns,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRows(rows)
+}
+
+func (s *Store) UpdateComment(id string, body string, editedAt time.Time) error {
+	_, err := s.db.Exec(
+		"UPDATE comments SET body = $1, editedAt = $2 WHERE id = $3;",
+		body,
+		editedAt,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteComment(id string, deletedAt time.Time) error {
+	_, err := s.db.Exec(
+		"UPDATE comments SET body = '', deletedAt = $1 WHERE id = $2;",
+		deletedAt,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) UpdateCommentStatus(id string, status string) error {
+	_, err := s.db.Exec("UPDATE comments SET status = $1 WHERE id = $2;", status, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) CountApprovedCommentsByAuthor(authorId string) (int, error) {
+	count := 0
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM comments WHERE authorId = $1 AND status = 'approved';",
+		authorId,
+	).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (s *Store) GetCommentsEnabled(blogId string) (bool, error) {
+	enabled := false
+	err := s.db.QueryRow(
+		"SELECT commentsEnabled FROM blogs WHERE id = $1;",
+		blogId,
+	).Scan(&enabled)
+	if err != nil {
+		return false, err
+	}
+
+	return enabled, nil
+}
+
+func (s *Store) SetCommentsEnabled(blogId string, enabled bool) error {
+	_, err := s.db.Exec(
+		"UPDATE blogs SET commentsEnabled = $1 WHERE id = $2;",
+		enabled,
+		blogId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanRows(rows *sql.Rows) ([]types_comment.Comment, error) {
+	comments := []types_comment.Comment{}
+
+	for rows.Next() {
+		comment, err := scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, *comment)
+	}
+
+	return comments, nil
+}
+
+func scanRow(rows *sql.Rows) (*types_comment.Comment, error) {
+	comment := new(types_comment.Comment)
+	var parentId, authorId sql.NullString
+	var editedAt, deletedAt sql.NullTime
+
+	err := rows.Scan(
+		&comment.Id,
+		&comment.BlogId,
+		&parentId,
+		&authorId,
+		&comment.Body,
+		&comment.Status,
+		&comment.CreatedAt,
+		&editedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.ParentId = parentId.String
+	comment.AuthorId = authorId.String
+
+	if editedAt.Valid {
+		comment.EditedAt = &editedAt.Time
+	}
+
+	if deletedAt.Valid {
+		comment.DeletedAt = &deletedAt.Time
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+
+	"github.com/SaeedAlian/megavault/api/config"
+	"github.com/SaeedAlian/megavault/api/types/mail"
+)
+
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(
+	host string,
+	port string,
+	username string,
+	password string,
+	from string,
+) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: fmt.Sprintf("%s:%s", host, port),
+		from: from,
+		auth: auth,
+	}
+}
+
+func (m *SMTPMailer) Send(mail types_mail.Mail) error {
+	msg := strings.Join([]string{
+		fmt.Sprintf("From: %s", m.from),
+		fmt.Sprintf("To: %s", mail.To),
+		fmt.Sprintf("Subject: %s", mail.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+		"",
+		mail.Body,
+	}, "\r\n")
+
+	return smtp.SendMail(m.addr, m.auth, m.from, []string{mail.To}, []byte(msg))
+}
+
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(mail types_mail.Mail) error {
+	log.Printf("mail to %s: %s\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
+
+func NewMailer() types_mail.Mailer {
+	if config.Env.SMTPHost == "" {
+		return NewLogMailer()
+	}
+
+	return NewSMTPMailer(
+		config.Env.SMTPHost,
+		config.Env.SMTPPort,
+		config.Env.SMTPUser,
+		config.Env.SMTPPassword,
+		config.Env.MailFrom,
+	)
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+// The links in the email change mails only open a page asking the user to
+// confirm, since mail scanners and link previews fetch every link they see and
+// a GET must not change the account on their behalf.
+var emailChangePage = template.Must(template.New("email-change").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+<form method="POST" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>
+`))
+
+type emailChangePageData struct {
+	Title   string
+	Message string
+	Action  string
+	Token   string
+	Button  string
+}
+
+func (h *Handler) confirmEmailChangePage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Token not found")
+		return
+	}
+
+	change, err := h.store.GetEmailChangeByTokenHash(auth.HashToken(token))
+	if err != nil || change == nil || change.ConfirmedAt != nil || change.RevertedAt != nil ||
+		time.Now().After(change.ExpiresAt) {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	writeEmailChangePage(w, emailChangePageData{
+		Title:   "Confirm your new email address",
+		Message: "Change the email address of your account to " + change.NewEmail + "?",
+		Action:  r.URL.Path,
+		Token:   token,
+		Button:  "Confirm",
+	})
+}
+
+func (h *Handler) undoEmailChangePage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Token not found")
+		return
+	}
+
+	change, err := h.store.GetEmailChangeByUndoTokenHash(auth.HashToken(token))
+	if err != nil || change == nil || change.RevertedAt != nil ||
+		time.Now().After(change.UndoExpiresAt) {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	writeEmailChangePage(w, emailChangePageData{
+		Title:   "Undo the email change",
+		Message: "Keep " + change.OldEmail + " as the email address of your account?",
+		Action:  r.URL.Path,
+		Token:   token,
+		Button:  "Undo",
+	})
+}
+
+func writeEmailChangePage(w http.ResponseWriter, data emailChangePageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	emailChangePage.Execute(w, data)
+}
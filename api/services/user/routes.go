@@ -3,32 +3,50 @@ package user
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 
+	"github.com/SaeedAlian/megavault/api/config"
 	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/types/mail"
 	"github.com/SaeedAlian/megavault/api/types/user"
 	"github.com/SaeedAlian/megavault/api/utils"
 )
 
 type Handler struct {
-	store types_user.UserStore
+	store  types_user.UserStore
+	mailer types_mail.Mailer
 }
 
-func NewHandler(store types_user.UserStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store types_user.UserStore, mailer types_mail.Mailer) *Handler {
+	return &Handler{store: store, mailer: mailer}
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/", auth.WithJWTAuth(h.getUsers, h.store)).Methods("GET")
 	router.HandleFunc("/me", auth.WithJWTAuth(h.getMe, h.store)).Methods("GET")
 	router.HandleFunc("/{id}", auth.WithJWTAuth(h.getUser, h.store)).Methods("GET")
+	router.HandleFunc("/me/email", auth.WithJWTAuth(h.changeEmail, h.store)).Methods("POST")
+	router.HandleFunc("/me/username", auth.WithJWTAuth(h.changeUsername, h.store)).Methods("PATCH")
+	router.HandleFunc("/me/preferences", auth.WithJWTAuth(h.getPreferences, h.store)).Methods("GET")
+	router.HandleFunc("/me/preferences", auth.WithJWTAuth(h.updatePreferences, h.store)).
+		Methods("PATCH")
+	router.HandleFunc("/{id}/follow", auth.WithJWTAuth(h.followUser, h.store)).Methods("POST")
+	router.HandleFunc("/{id}/follow", auth.WithJWTAuth(h.unfollowUser, h.store)).Methods("DELETE")
+	router.HandleFunc("/profile/{username}", auth.WithOptionalJWTAuth(h.getProfile, h.store)).
+		Methods("GET")
 
 	router.HandleFunc("/register", h.register).Methods("POST")
 	router.HandleFunc("/login", h.login).Methods("POST")
+	router.HandleFunc("/email/confirm", h.confirmEmailChangePage).Methods("GET")
+	router.HandleFunc("/email/confirm", h.confirmEmailChange).Methods("POST")
+	router.HandleFunc("/email/undo", h.undoEmailChangePage).Methods("GET")
+	router.HandleFunc("/email/undo", h.undoEmailChange).Methods("POST")
 }
 
 func (h *Handler) login(w http.ResponseWriter, r *http.Request) {
@@ -97,6 +115,15 @@ func (h *Handler) register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.isUsernameReserved(strings.ToLower(user.Username), "") {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"This username was released recently and cannot be claimed yet",
+		)
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(user.Password)
 	if err != nil {
 		utils.WriteErrorInResponse(
@@ -127,33 +154,114 @@ func (h *Handler) register(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) getUsers(w http.ResponseWriter, r *http.Request) {
-	usernameQuery := r.URL.Query().Get("username")
+	params := r.URL.Query()
 
 	query := types_user.SearchUserQuery{
-		Username: strings.ToLower(usernameQuery),
+		Keyword:   strings.ToLower(params.Get("keyword")),
+		Username:  strings.ToLower(params.Get("username")),
+		Email:     strings.ToLower(params.Get("email")),
+		Fuzzy:     params.Get("fuzzy") == "true",
+		SortBy:    params.Get("sortBy"),
+		SortOrder: strings.ToLower(params.Get("sortOrder")),
+		Cursor:    params.Get("cursor"),
+		Limit:     20,
 	}
 
-	users, err := h.store.GetUsers(query)
-	if err != nil {
+	if query.SortBy == "" && query.Fuzzy && query.Keyword != "" {
+		query.SortBy = "relevance"
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+
+		query.Limit = l
+	}
+
+	if offset := params.Get("offset"); offset != "" {
+		o, err := strconv.Atoi(offset)
+		if err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid offset")
+			return
+		}
+
+		query.Offset = o
+	}
+
+	if err := utils.Validator.Struct(query); err != nil {
+		errors := err.(validator.ValidationErrors)
 		utils.WriteErrorInResponse(
 			w,
-			http.StatusInternalServerError,
-			"An error occurred",
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid query: %v", errors),
 		)
 		return
 	}
 
-	if len(users) == 0 {
+	if query.Cursor != "" {
+		if query.SortBy == "relevance" {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"Cursor pagination is not supported when sorting by relevance",
+			)
+			return
+		}
+
+		if _, err := utils.DecodeCursor(query.Cursor); err != nil {
+			utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+	}
+
+	query.IncludeEmail = auth.IsAdmin(r, h.store)
+
+	// One more user than the limit is fetched, so a next cursor is only given
+	// out when there really is another page.
+	fetch := query
+	fetch.Limit++
+
+	users, total, err := h.store.GetUsers(fetch)
+	if err != nil {
 		utils.WriteErrorInResponse(
 			w,
-			http.StatusNotFound,
-			"No user found",
+			http.StatusInternalServerError,
+			"An error occurred",
 		)
 		return
 	}
 
-	payload := map[string][]types_user.User{
-		"result": users,
+	if users == nil {
+		users = []types_user.User{}
+	}
+
+	hasMore := len(users) > query.Limit
+	if hasMore {
+		users = users[:query.Limit]
+	}
+
+	if !query.IncludeEmail {
+		for i := range users {
+			users[i].Email = ""
+		}
+	}
+
+	payload := types_user.UserSearchResult{
+		Result: users,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+
+	if hasMore && query.SortBy != "relevance" {
+		last := users[len(users)-1]
+		payload.NextCursor = utils.EncodeCursor(utils.Cursor{
+			Value: userSortValue(last, query.SortBy),
+			Id:    last.Id,
+		})
 	}
 
 	utils.WriteJSONInResponse(w, http.StatusOK, payload, nil)
@@ -181,7 +289,7 @@ func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.WriteJSONInResponse(w, http.StatusOK, u, nil)
+	h.writeProfile(w, r, u)
 }
 
 func (h *Handler) getMe(w http.ResponseWriter, r *http.Request) {
@@ -208,3 +316,563 @@ func (h *Handler) getMe(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSONInResponse(w, http.StatusOK, u, nil)
 }
+
+func (h *Handler) changeEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return
+	}
+
+	var payload types_user.ChangeEmailPayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid email payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	u, err := h.store.GetUserById(userId.(string))
+	if err != nil || u == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Invalid user id")
+		return
+	}
+
+	if isPasswordCorrect := auth.ComparePassword(payload.Password, u.Password); !isPasswordCorrect {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid credentials")
+		return
+	}
+
+	newEmail := strings.ToLower(payload.Email)
+	if newEmail == u.Email {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The new email is the same as the current one",
+		)
+		return
+	}
+
+	if existing, _ := h.store.GetUserByEmail(newEmail); existing != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another user with this email already exists",
+		)
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateToken()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	undoToken, undoTokenHash, err := auth.GenerateToken()
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	now := time.Now()
+	change, err := h.store.CreateEmailChange(types_user.CreateEmailChangePayload{
+		UserId:        u.Id,
+		OldEmail:      u.Email,
+		NewEmail:      newEmail,
+		TokenHash:     tokenHash,
+		UndoTokenHash: undoTokenHash,
+		ExpiresAt:     now.Add(24 * time.Hour),
+		UndoExpiresAt: now.Add(7 * 24 * time.Hour),
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	err = h.mailer.Send(types_mail.Mail{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your new email address by opening the link below:\n\n%s\n\nThis link expires in 24 hours.",
+			u.FirstName,
+			fmt.Sprintf("%s/api/v1/user/email/confirm?token=%s", config.Env.Host, token),
+		),
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusInternalServerError,
+			"Failed to send the confirmation email",
+		)
+		return
+	}
+
+	err = h.mailer.Send(types_mail.Mail{
+		To:      u.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nA request was made to change your account email to %s.\nIf this wasn't you, undo the change by opening the link below:\n\n%s\n\nThis link expires in 7 days.",
+			u.FirstName,
+			newEmail,
+			fmt.Sprintf("%s/api/v1/user/email/undo?token=%s", config.Env.Host, undoToken),
+		),
+	})
+	if err != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusInternalServerError,
+			"Failed to send the notice email",
+		)
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusAccepted, change, nil)
+}
+
+func (h *Handler) confirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	if token == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Token not found")
+		return
+	}
+
+	change, err := h.store.GetEmailChangeByTokenHash(auth.HashToken(token))
+	if err != nil || change == nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	if change.ConfirmedAt != nil || change.RevertedAt != nil ||
+		time.Now().After(change.ExpiresAt) {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	if existing, _ := h.store.GetUserByEmail(change.NewEmail); existing != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another user with this email already exists",
+		)
+		return
+	}
+
+	if err := h.store.ConfirmEmailChange(change.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf(
+				"Your email has been changed to %s successfully",
+				change.NewEmail,
+			),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) undoEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	if token == "" {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Token not found")
+		return
+	}
+
+	change, err := h.store.GetEmailChangeByUndoTokenHash(auth.HashToken(token))
+	if err != nil || change == nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	if change.RevertedAt != nil || time.Now().After(change.UndoExpiresAt) {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid or expired token")
+		return
+	}
+
+	if change.ConfirmedAt != nil {
+		existing, _ := h.store.GetUserByEmail(change.OldEmail)
+		if existing != nil && existing.Id != change.UserId {
+			utils.WriteErrorInResponse(
+				w,
+				http.StatusBadRequest,
+				"Another user with this email already exists",
+			)
+			return
+		}
+	}
+
+	if err := h.store.RevertEmailChange(change.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("Your email has been kept as %s", change.OldEmail),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) changeUsername(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return
+	}
+
+	var payload types_user.ChangeUsernamePayload
+	if err := utils.ParseJSONFromRequest(r, &payload); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid username payload")
+		return
+	}
+
+	if err := utils.Validator.Struct(payload); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	u, err := h.store.GetUserById(userId.(string))
+	if err != nil || u == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "Invalid user id")
+		return
+	}
+
+	if isPasswordCorrect := auth.ComparePassword(payload.Password, u.Password); !isPasswordCorrect {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid credentials")
+		return
+	}
+
+	newUsername := strings.ToLower(payload.Username)
+	if newUsername == u.Username {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"The new username is the same as the current one",
+		)
+		return
+	}
+
+	history, err := h.store.GetUsernameHistory(u.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	cooldown := time.Duration(config.Env.UsernameChangeCooldownDays) * 24 * time.Hour
+	if len(history) > 0 && time.Since(history[0].ChangedAt) < cooldown {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusTooManyRequests,
+			fmt.Sprintf(
+				"You can change your username again after %s",
+				history[0].ChangedAt.Add(cooldown).Format(time.RFC1123),
+			),
+		)
+		return
+	}
+
+	if existing, _ := h.store.GetUserByUsername(newUsername); existing != nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Another user with this username already exists",
+		)
+		return
+	}
+
+	if h.isUsernameReserved(newUsername, u.Id) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"This username was released recently and cannot be claimed yet",
+		)
+		return
+	}
+
+	if err := h.store.ChangeUsername(u.Id, newUsername); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf(
+				"Your username has been changed to %s successfully",
+				newUsername,
+			),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) getProfile(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	username, ok := vars["username"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"Username not found",
+		)
+		return
+	}
+
+	username = strings.ToLower(username)
+
+	if u, err := h.store.GetUserByUsername(username); err == nil && u != nil {
+		h.writeProfile(w, r, u)
+		return
+	}
+
+	history, err := h.store.GetLatestUsernameHistoryByUsername(username)
+	if err != nil || history == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	u, err := h.store.GetUserById(history.UserId)
+	if err != nil || u == nil {
+		utils.WriteErrorInResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	location, err := mux.CurrentRoute(r).URL("username", u.Username)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+}
+
+func (h *Handler) followUser(w http.ResponseWriter, r *http.Request) {
+	followerId, followee, ok := h.getFollowTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.FollowUser(followerId, followee.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("You are now following %s", followee.Username),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) unfollowUser(w http.ResponseWriter, r *http.Request) {
+	followerId, followee, ok := h.getFollowTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.store.UnfollowUser(followerId, followee.Id); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(
+		w,
+		http.StatusOK,
+		map[string]string{
+			"message": fmt.Sprintf("You are no longer following %s", followee.Username),
+		},
+		nil,
+	)
+}
+
+func (h *Handler) getFollowTarget(
+	w http.ResponseWriter,
+	r *http.Request,
+) (string, *types_user.User, bool) {
+	userId := r.Context().Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return "", nil, false
+	}
+
+	vars := mux.Vars(r)
+	followeeId, ok := vars["id"]
+	if !ok {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found",
+		)
+		return "", nil, false
+	}
+
+	if followeeId == userId.(string) {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"You cannot follow yourself",
+		)
+		return "", nil, false
+	}
+
+	followee, err := h.store.GetUserById(followeeId)
+	if err != nil || followee == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusNotFound,
+			"User not found",
+		)
+		return "", nil, false
+	}
+
+	return userId.(string), followee, true
+}
+
+// writeProfile leaves the email out unless the profile is the viewer's own or
+// the viewer is an admin.
+func (h *Handler) writeProfile(w http.ResponseWriter, r *http.Request, u *types_user.User) {
+	followers, following, err := h.store.GetFollowCounts(u.Id)
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	user := *u
+	if viewerId, _ := r.Context().Value("userId").(string); viewerId != u.Id && !auth.IsAdmin(r, h.store) {
+		user.Email = ""
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, types_user.Profile{
+		User:           user,
+		FollowersCount: followers,
+		FollowingCount: following,
+	}, nil)
+}
+
+func (h *Handler) isUsernameReserved(username string, userId string) bool {
+	history, err := h.store.GetLatestUsernameHistoryByUsername(username)
+	if err != nil || history == nil || history.UserId == userId {
+		return false
+	}
+
+	reservation := time.Duration(config.Env.UsernameReservationDays) * 24 * time.Hour
+
+	return time.Since(history.ChangedAt) < reservation
+}
+
+func (h *Handler) getPreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return
+	}
+
+	preferences, err := h.store.GetPreferences(userId.(string))
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, preferences, nil)
+}
+
+func (h *Handler) updatePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	if userId == nil {
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			"User id not found within the authorization token",
+		)
+		return
+	}
+
+	preferences, err := h.store.GetPreferences(userId.(string))
+	if err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	// Decoding the patch over the current document only overrides the keys
+	// present in the request body.
+	if err := utils.ParseJSONFromRequest(r, preferences); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusBadRequest, "Invalid preferences payload")
+		return
+	}
+
+	preferences.Version = types_user.PreferencesVersion
+
+	if err := utils.Validator.Struct(preferences); err != nil {
+		errors := err.(validator.ValidationErrors)
+		utils.WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Invalid payload: %v", errors),
+		)
+		return
+	}
+
+	if err := h.store.UpdatePreferences(userId.(string), *preferences); err != nil {
+		utils.WriteErrorInResponse(w, http.StatusInternalServerError, "An error occurred")
+		return
+	}
+
+	utils.WriteJSONInResponse(w, http.StatusOK, preferences, nil)
+}
+
+func userSortValue(u types_user.User, sortBy string) string {
+	switch sortBy {
+	case "username":
+		return u.Username
+	case "firstname":
+		return u.FirstName
+	case "lastname":
+		return u.LastName
+	default:
+		return u.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
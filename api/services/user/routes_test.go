@@ -2,11 +2,13 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
@@ -15,6 +17,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/SaeedAlian/megavault/api/services/auth"
+	"github.com/SaeedAlian/megavault/api/types/mail"
 	"github.com/SaeedAlian/megavault/api/types/user"
 )
 
@@ -56,7 +59,8 @@ func TestUserService(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(&userStore)
+	mailer := MockMailer{}
+	handler := NewHandler(&userStore, &mailer)
 
 	t.Run("should get all users", func(t *testing.T) {
 		req, err := http.NewRequest("GET", "/user", nil)
@@ -76,8 +80,68 @@ func TestUserService(t *testing.T) {
 		}
 	})
 
+	t.Run("should hide the email of other users", func(t *testing.T) {
+		getUser := func(viewerId string) types_user.Profile {
+			req, err := http.NewRequest("GET", "/2", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", viewerId))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/{id}", handler.getUser).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+
+			var profile types_user.Profile
+			if err := json.NewDecoder(rr.Body).Decode(&profile); err != nil {
+				t.Fatal(err)
+			}
+
+			return profile
+		}
+
+		if profile := getUser("1"); profile.Email != "" {
+			t.Errorf("Expected the email to be hidden, received %s", profile.Email)
+		}
+
+		if profile := getUser("2"); profile.Email != "maryjane@gmail.com" {
+			t.Errorf("Expected the own email to be shown, received %q", profile.Email)
+		}
+
+		req, err := http.NewRequest("GET", "/user", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		var res types_user.UserSearchResult
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, u := range res.Result {
+			if u.Email != "" {
+				t.Errorf("Expected the emails to be hidden, received %s", u.Email)
+			}
+		}
+	})
+
 	t.Run(
-		"should fail on getting all users with 404 status because of wrong query",
+		"should get an empty list of users with 200 status because of wrong query",
 		func(t *testing.T) {
 			req, err := http.NewRequest("GET", "/user?username=wrongusername", nil)
 			if err != nil {
@@ -91,8 +155,154 @@ func TestUserService(t *testing.T) {
 
 			router.ServeHTTP(rr, req)
 
-			if rr.Code != http.StatusNotFound {
-				t.Errorf("Expected code %d, received %d", http.StatusNotFound, rr.Code)
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+
+			var res types_user.UserSearchResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Result == nil || len(res.Result) != 0 || res.Total != 0 {
+				t.Errorf("Expected an empty result, received %v", res)
+			}
+		},
+	)
+
+	t.Run(
+		"should paginate users with limit and offset",
+		func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/user?limit=2&offset=0&sortBy=username", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+			}
+
+			var res types_user.UserSearchResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Result) != 2 {
+				t.Errorf("Expected 2 users, received %d", len(res.Result))
+			}
+
+			if res.Total != len(userStore.DefaultUsers) {
+				t.Errorf("Expected total %d, received %d", len(userStore.DefaultUsers), res.Total)
+			}
+
+			if res.NextCursor == "" {
+				t.Error("Expected a next cursor, received none")
+			}
+		},
+	)
+
+	t.Run(
+		"should not give a next cursor on the last full page",
+		func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/user?limit=3&sortBy=username", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			var res types_user.UserSearchResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Result) != len(userStore.DefaultUsers) || res.NextCursor != "" {
+				t.Errorf("Expected every user without a next cursor, received %+v", res)
+			}
+		},
+	)
+
+	t.Run(
+		"should search users by name with keyword",
+		func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/user?keyword=Turing", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			var res types_user.UserSearchResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Result) != 1 || res.Result[0].Username != "alanturing00" {
+				t.Errorf("Expected to find alanturing00, received %v", res.Result)
+			}
+		},
+	)
+
+	t.Run(
+		"should not search by email for non-admin users",
+		func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/user?keyword=alanturing0090@gmail.com", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			var res types_user.UserSearchResult
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res.Result) != 0 {
+				t.Errorf("Expected no users, received %d", len(res.Result))
+			}
+		},
+	)
+
+	t.Run(
+		"should fail on getting users because of invalid sort field",
+		func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/user?sortBy=password", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/user", handler.getUsers).Methods("GET")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
 			}
 		},
 	)
@@ -605,112 +815,649 @@ func TestUserService(t *testing.T) {
 			t.Error("Expected for the created user to not be found, but it has been found")
 		}
 	})
-}
+	t.Run("should change email after confirmation and undo it", func(t *testing.T) {
+		payload := types_user.ChangeEmailPayload{
+			Email:    "John.New@gmail.com",
+			Password: "password",
+		}
 
-type MockUserStore struct {
-	DefaultUsers []types_user.User
-}
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-type MockGetUsersResult struct {
-	Result map[string][]types_user.User
-}
+		req, err := http.NewRequest("POST", "/me/email", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
 
-func (m *MockUserStore) GetUserById(id string) (*types_user.User, error) {
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
 
-		if u.Id == id {
-			return &u, nil
-		}
-	}
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
 
-	return nil, fmt.Errorf("Cannot find user")
-}
+		router.HandleFunc("/me/email", handler.changeEmail).Methods("POST")
 
-func (m *MockUserStore) GetUserByUsername(username string) (*types_user.User, error) {
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		router.ServeHTTP(rr, req)
 
-		if u.Username == username {
-			return &u, nil
+		if rr.Code != http.StatusAccepted {
+			t.Fatalf("Expected code %d, received %d", http.StatusAccepted, rr.Code)
 		}
-	}
-
-	return nil, fmt.Errorf("Cannot find user")
-}
 
-func (m *MockUserStore) GetUserByEmail(email string) (*types_user.User, error) {
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		if len(mailer.Sent) != 2 {
+			t.Fatalf("Expected 2 mails to be sent, received %d", len(mailer.Sent))
+		}
 
-		if u.Email == email {
-			return &u, nil
+		if mailer.Sent[0].To != "john.new@gmail.com" || mailer.Sent[1].To != "johndoe@gmail.com" {
+			t.Errorf("Expected mails to be sent to both addresses, received %v", mailer.Sent)
 		}
-	}
 
-	return nil, fmt.Errorf("Cannot find user")
-}
+		u, _ := handler.store.GetUserById("1")
+		if u.Email != "johndoe@gmail.com" {
+			t.Errorf("Expected email to stay unchanged before confirmation, received %s", u.Email)
+		}
 
-func (m *MockUserStore) GetUserByUsernameOrEmail(
-	username string,
-	email string,
-) (*types_user.User, error) {
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		confirmToken := extractToken(mailer.Sent[0].Body)
+		undoToken := extractToken(mailer.Sent[1].Body)
 
-		if u.Email == email || u.Username == username {
-			return &u, nil
+		req, err = http.NewRequest("GET", "/email/confirm?token="+confirmToken, nil)
+		if err != nil {
+			t.Fatal(err)
 		}
-	}
-
-	return nil, fmt.Errorf("Cannot find user")
-}
 
-func (m *MockUserStore) CreateUser(u types_user.RegisterUserPayload) (*types_user.User, error) {
-	created := types_user.User{
-		Id:        strconv.Itoa(rand.Int()),
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Email:     u.Email,
-		Username:  u.Username,
-		Password:  u.Password,
-		CreatedAt: time.Now(),
-	}
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
 
-	m.DefaultUsers = append(m.DefaultUsers, created)
+		router.HandleFunc("/email/confirm", handler.confirmEmailChangePage).Methods("GET")
+		router.HandleFunc("/email/confirm", handler.confirmEmailChange).Methods("POST")
 
-	return &created, nil
-}
+		router.ServeHTTP(rr, req)
 
-func (m *MockUserStore) GetUsers(
-	query types_user.SearchUserQuery,
-) ([]types_user.User, error) {
-	var res []types_user.User
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
 
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		if !strings.Contains(rr.Body.String(), `<form method="POST"`) {
+			t.Errorf("Expected a confirmation form, received %s", rr.Body.String())
+		}
 
-		if len(query.Username) > 0 {
-			if strings.Contains(u.Username, query.Username) {
-				res = append(res, u)
-			}
-		} else {
-			res = append(res, u)
+		u, _ = handler.store.GetUserById("1")
+		if u.Email != "johndoe@gmail.com" {
+			t.Errorf("Expected email to stay unchanged on GET, received %s", u.Email)
 		}
-	}
 
-	return res, nil
-}
+		req, err = http.NewRequest(
+			"POST",
+			"/email/confirm",
+			strings.NewReader(url.Values{"token": {confirmToken}}.Encode()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-func (m *MockUserStore) DeleteUserById(
-	id string,
-) error {
-	var res []types_user.User
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	for i := range m.DefaultUsers {
-		u := m.DefaultUsers[i]
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
 
-		if u.Id != id {
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		u, _ = handler.store.GetUserById("1")
+		if u.Email != "john.new@gmail.com" {
+			t.Errorf("Expected email to be changed after confirmation, received %s", u.Email)
+		}
+
+		req, err = http.NewRequest(
+			"POST",
+			"/email/undo",
+			strings.NewReader(url.Values{"token": {undoToken}}.Encode()),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/email/undo", handler.undoEmailChange).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		u, _ = handler.store.GetUserById("1")
+		if u.Email != "johndoe@gmail.com" {
+			t.Errorf("Expected email to be reverted after undo, received %s", u.Email)
+		}
+	})
+
+	t.Run("should fail to change email because it's already taken", func(t *testing.T) {
+		payload := types_user.ChangeEmailPayload{
+			Email:    "maryjane@gmail.com",
+			Password: "password",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/me/email", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/me/email", handler.changeEmail).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("should fail to confirm email change because of invalid token", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/email/confirm?token=invalidtoken", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/email/confirm", handler.confirmEmailChange).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+	t.Run("should change username and redirect the old one", func(t *testing.T) {
+		payload := types_user.ChangeUsernamePayload{
+			Username: "MaryJane99",
+			Password: "password",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PATCH", "/me/username", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "2"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/me/username", handler.changeUsername).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/profile/maryjane12", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/profile/{username}", handler.getProfile).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusMovedPermanently {
+			t.Errorf("Expected code %d, received %d", http.StatusMovedPermanently, rr.Code)
+		}
+
+		if location := rr.Header().Get("Location"); location != "/profile/maryjane99" {
+			t.Errorf("Expected redirect to /profile/maryjane99, received %s", location)
+		}
+
+		req, err = http.NewRequest("GET", "/api/v1/user/profile/MaryJane12", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		handler.RegisterRoutes(router.PathPrefix("/api/v1/user").Subrouter())
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusMovedPermanently {
+			t.Errorf("Expected code %d, received %d", http.StatusMovedPermanently, rr.Code)
+		}
+
+		expected := "/api/v1/user/profile/maryjane99"
+		if location := rr.Header().Get("Location"); location != expected {
+			t.Errorf("Expected redirect to %s, received %s", expected, location)
+		}
+	})
+
+	t.Run("should fail to change username to an invalid one", func(t *testing.T) {
+		for _, username := range []string{"ab", "mary/jane", "mary jane", strings.Repeat("a", 31)} {
+			payload := types_user.ChangeUsernamePayload{
+				Username: username,
+				Password: "password",
+			}
+
+			marshalled, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest("PATCH", "/me/username", bytes.NewBuffer(marshalled))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = req.WithContext(context.WithValue(req.Context(), "userId", "3"))
+
+			rr := httptest.NewRecorder()
+			router := mux.NewRouter()
+
+			router.HandleFunc("/me/username", handler.changeUsername).Methods("PATCH")
+
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf(
+					"Expected code %d for %q, received %d",
+					http.StatusBadRequest,
+					username,
+					rr.Code,
+				)
+			}
+		}
+	})
+
+	t.Run("should fail to change username again during the cooldown", func(t *testing.T) {
+		payload := types_user.ChangeUsernamePayload{
+			Username: "maryjane100",
+			Password: "password",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("PATCH", "/me/username", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "2"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/me/username", handler.changeUsername).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected code %d, received %d", http.StatusTooManyRequests, rr.Code)
+		}
+	})
+
+	t.Run("should fail to register with a recently released username", func(t *testing.T) {
+		payload := types_user.RegisterUserPayload{
+			FirstName: "Mary",
+			LastName:  "Jane",
+			Username:  "maryjane12",
+			Email:     "anothermary@gmail.com",
+			Password:  "password",
+		}
+
+		marshalled, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest("POST", "/register", bytes.NewBuffer(marshalled))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/register", handler.register).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+	t.Run("should update preferences and keep defaults for missing keys", func(t *testing.T) {
+		req, err := http.NewRequest(
+			"PATCH",
+			"/me/preferences",
+			bytes.NewBufferString(`{"theme":"dark","editor":{"fontSize":18}}`),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "3"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/me/preferences", handler.updatePreferences).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		p, _ := handler.store.GetPreferences("3")
+		defaults := types_user.DefaultPreferences()
+
+		if p.Theme != "dark" || p.Editor.FontSize != 18 {
+			t.Errorf("Expected preferences to be updated, received %v", p)
+		}
+
+		if p.Language != defaults.Language || p.Editor.TabSize != defaults.Editor.TabSize {
+			t.Errorf("Expected missing keys to keep their defaults, received %v", p)
+		}
+	})
+
+	t.Run("should fail to update preferences because of invalid theme", func(t *testing.T) {
+		req, err := http.NewRequest(
+			"PATCH",
+			"/me/preferences",
+			bytes.NewBufferString(`{"theme":"purple"}`),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "3"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/me/preferences", handler.updatePreferences).Methods("PATCH")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+
+		p, _ := handler.store.GetPreferences("3")
+		if p.Theme != "dark" {
+			t.Errorf("Expected theme to stay dark, received %s", p.Theme)
+		}
+	})
+	t.Run("should follow a user and show the counts on the profile", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/user/3/follow", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/user/{id}/follow", handler.followUser).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		req, err = http.NewRequest("GET", "/user/3", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr = httptest.NewRecorder()
+		router = mux.NewRouter()
+
+		router.HandleFunc("/user/{id}", handler.getUser).Methods("GET")
+
+		router.ServeHTTP(rr, req)
+
+		var profile types_user.Profile
+		if err := json.NewDecoder(rr.Body).Decode(&profile); err != nil {
+			t.Fatal(err)
+		}
+
+		if profile.Id != "3" || profile.FollowersCount != 1 || profile.FollowingCount != 0 {
+			t.Errorf("Expected profile of user 3 with 1 follower, received %v", profile)
+		}
+	})
+
+	t.Run("should unfollow a user", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/user/3/follow", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/user/{id}/follow", handler.unfollowUser).Methods("DELETE")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("Expected code %d, received %d", http.StatusOK, rr.Code)
+		}
+
+		if followers, _, _ := handler.store.GetFollowCounts("3"); followers != 0 {
+			t.Errorf("Expected user 3 to have no followers, received %d", followers)
+		}
+	})
+
+	t.Run("should fail to follow yourself", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/user/1/follow", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), "userId", "1"))
+
+		rr := httptest.NewRecorder()
+		router := mux.NewRouter()
+
+		router.HandleFunc("/user/{id}/follow", handler.followUser).Methods("POST")
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, received %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func extractToken(body string) string {
+	_, after, found := strings.Cut(body, "token=")
+	if !found {
+		return ""
+	}
+
+	return strings.Fields(after)[0]
+}
+
+type MockUserStore struct {
+	DefaultUsers []types_user.User
+	EmailChanges []types_user.EmailChange
+	History      []types_user.UsernameHistory
+	Preferences  map[string]types_user.Preferences
+	Follows      [][2]string
+}
+
+type MockMailer struct {
+	Sent []types_mail.Mail
+}
+
+func (m *MockMailer) Send(mail types_mail.Mail) error {
+	m.Sent = append(m.Sent, mail)
+	return nil
+}
+
+type MockGetUsersResult struct {
+	Result map[string][]types_user.User
+}
+
+func (m *MockUserStore) GetUserById(id string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Id == id {
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find user")
+}
+
+func (m *MockUserStore) GetUserByUsername(username string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Username == username {
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find user")
+}
+
+func (m *MockUserStore) GetUserByEmail(email string) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Email == email {
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find user")
+}
+
+func (m *MockUserStore) GetUserByUsernameOrEmail(
+	username string,
+	email string,
+) (*types_user.User, error) {
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Email == email || u.Username == username {
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find user")
+}
+
+func (m *MockUserStore) CreateUser(u types_user.RegisterUserPayload) (*types_user.User, error) {
+	created := types_user.User{
+		Id:        strconv.Itoa(rand.Int()),
+		FirstName: u.FirstName,
+		LastName:  u.LastName,
+		Email:     u.Email,
+		Username:  u.Username,
+		Password:  u.Password,
+		CreatedAt: time.Now(),
+	}
+
+	m.DefaultUsers = append(m.DefaultUsers, created)
+
+	return &created, nil
+}
+
+func (m *MockUserStore) GetUsers(
+	query types_user.SearchUserQuery,
+) ([]types_user.User, int, error) {
+	var res []types_user.User
+
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if len(query.Username) > 0 && !strings.Contains(u.Username, query.Username) {
+			continue
+		}
+
+		if len(query.Email) > 0 && query.IncludeEmail && !strings.Contains(u.Email, query.Email) {
+			continue
+		}
+
+		if len(query.Keyword) > 0 {
+			fields := []string{u.Username, u.FirstName, u.LastName}
+			if query.IncludeEmail {
+				fields = append(fields, u.Email)
+			}
+
+			found := false
+			for _, f := range fields {
+				if strings.Contains(strings.ToLower(f), query.Keyword) {
+					found = true
+				}
+			}
+
+			if !found {
+				continue
+			}
+		}
+
+		res = append(res, u)
+	}
+
+	total := len(res)
+
+	if query.Offset >= len(res) {
+		return []types_user.User{}, total, nil
+	}
+
+	res = res[query.Offset:]
+	if len(res) > query.Limit {
+		res = res[:query.Limit]
+	}
+
+	return res, total, nil
+}
+
+func (m *MockUserStore) DeleteUserById(
+	id string,
+) error {
+	var res []types_user.User
+
+	for i := range m.DefaultUsers {
+		u := m.DefaultUsers[i]
+
+		if u.Id != id {
 			res = append(res, u)
 		}
 	}
@@ -745,3 +1492,207 @@ func (m *MockUserStore) DeleteUserByUsername(
 
 	return nil
 }
+
+func (m *MockUserStore) CreateEmailChange(
+	change types_user.CreateEmailChangePayload,
+) (*types_user.EmailChange, error) {
+	created := types_user.EmailChange{
+		Id:            strconv.Itoa(rand.Int()),
+		UserId:        change.UserId,
+		OldEmail:      change.OldEmail,
+		NewEmail:      change.NewEmail,
+		TokenHash:     change.TokenHash,
+		UndoTokenHash: change.UndoTokenHash,
+		ExpiresAt:     change.ExpiresAt,
+		UndoExpiresAt: change.UndoExpiresAt,
+		CreatedAt:     time.Now(),
+	}
+
+	m.EmailChanges = append(m.EmailChanges, created)
+
+	return &created, nil
+}
+
+func (m *MockUserStore) GetEmailChangeByTokenHash(
+	tokenHash string,
+) (*types_user.EmailChange, error) {
+	for i := range m.EmailChanges {
+		c := m.EmailChanges[i]
+
+		if c.TokenHash == tokenHash {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find email change")
+}
+
+func (m *MockUserStore) GetEmailChangeByUndoTokenHash(
+	undoTokenHash string,
+) (*types_user.EmailChange, error) {
+	for i := range m.EmailChanges {
+		c := m.EmailChanges[i]
+
+		if c.UndoTokenHash == undoTokenHash {
+			return &c, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find email change")
+}
+
+func (m *MockUserStore) ConfirmEmailChange(id string) error {
+	for i := range m.EmailChanges {
+		c := &m.EmailChanges[i]
+
+		if c.Id == id {
+			for j := range m.DefaultUsers {
+				if m.DefaultUsers[j].Id == c.UserId {
+					m.DefaultUsers[j].Email = c.NewEmail
+				}
+			}
+
+			now := time.Now()
+			c.ConfirmedAt = &now
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Email change not found to confirm")
+}
+
+func (m *MockUserStore) RevertEmailChange(id string) error {
+	for i := range m.EmailChanges {
+		c := &m.EmailChanges[i]
+
+		if c.Id == id {
+			if c.ConfirmedAt != nil {
+				for j := range m.DefaultUsers {
+					if m.DefaultUsers[j].Id == c.UserId && m.DefaultUsers[j].Email == c.NewEmail {
+						m.DefaultUsers[j].Email = c.OldEmail
+					}
+				}
+			}
+
+			now := time.Now()
+			c.RevertedAt = &now
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Email change not found to revert")
+}
+
+func (m *MockUserStore) ChangeUsername(id string, username string) error {
+	for i := range m.DefaultUsers {
+		u := &m.DefaultUsers[i]
+
+		if u.Id == id {
+			m.History = append(m.History, types_user.UsernameHistory{
+				Id:        strconv.Itoa(rand.Int()),
+				UserId:    u.Id,
+				Username:  u.Username,
+				ChangedAt: time.Now(),
+			})
+
+			u.Username = username
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("User not found to change username")
+}
+
+func (m *MockUserStore) GetUsernameHistory(userId string) ([]types_user.UsernameHistory, error) {
+	res := []types_user.UsernameHistory{}
+
+	for i := len(m.History) - 1; i >= 0; i-- {
+		if m.History[i].UserId == userId {
+			res = append(res, m.History[i])
+		}
+	}
+
+	return res, nil
+}
+
+func (m *MockUserStore) GetLatestUsernameHistoryByUsername(
+	username string,
+) (*types_user.UsernameHistory, error) {
+	for i := len(m.History) - 1; i >= 0; i-- {
+		h := m.History[i]
+
+		if h.Username == username {
+			return &h, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Cannot find username history")
+}
+
+func (m *MockUserStore) GetPreferences(userId string) (*types_user.Preferences, error) {
+	if p, ok := m.Preferences[userId]; ok {
+		return &p, nil
+	}
+
+	p := types_user.DefaultPreferences()
+
+	return &p, nil
+}
+
+func (m *MockUserStore) UpdatePreferences(
+	userId string,
+	preferences types_user.Preferences,
+) error {
+	if m.Preferences == nil {
+		m.Preferences = map[string]types_user.Preferences{}
+	}
+
+	m.Preferences[userId] = preferences
+
+	return nil
+}
+
+func (m *MockUserStore) FollowUser(followerId string, followeeId string) error {
+	for _, f := range m.Follows {
+		if f[0] == followerId && f[1] == followeeId {
+			return nil
+		}
+	}
+
+	m.Follows = append(m.Follows, [2]string{followerId, followeeId})
+
+	return nil
+}
+
+func (m *MockUserStore) UnfollowUser(followerId string, followeeId string) error {
+	var res [][2]string
+
+	for _, f := range m.Follows {
+		if f[0] != followerId || f[1] != followeeId {
+			res = append(res, f)
+		}
+	}
+
+	m.Follows = res
+
+	return nil
+}
+
+func (m *MockUserStore) GetFollowCounts(userId string) (int, int, error) {
+	followers, following := 0, 0
+
+	for _, f := range m.Follows {
+		if f[1] == userId {
+			followers++
+		}
+
+		if f[0] == userId {
+			following++
+		}
+	}
+
+	return followers, following, nil
+}
@@ -2,9 +2,12 @@ package user
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/SaeedAlian/megavault/api/types/user"
+	"github.com/SaeedAlian/megavault/api/utils"
 )
 
 type Store struct {
@@ -37,27 +40,137 @@ func (s *Store) CreateUser(user types_user.RegisterUserPayload) (*types_user.Use
 	return u, nil
 }
 
-func (s *Store) GetUsers(query types_user.SearchUserQuery) ([]types_user.User, error) {
+func (s *Store) GetUsers(query types_user.SearchUserQuery) ([]types_user.User, int, error) {
+	conditions := []string{}
+	args := []any{}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	searchColumns := []string{"username", "firstname", "lastname"}
+	if query.IncludeEmail {
+		searchColumns = append(searchColumns, "email")
+	}
+
+	keywordParam := ""
+	if query.Keyword != "" {
+		keywordParam = arg(query.Keyword)
+		matches := []string{}
+
+		for _, c := range searchColumns {
+			if query.Fuzzy {
+				matches = append(matches, fmt.Sprintf("%s %% %s", c, keywordParam))
+			} else {
+				matches = append(
+					matches,
+					fmt.Sprintf("%s ILIKE '%%' || %s || '%%'", c, keywordParam),
+				)
+			}
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s)", strings.Join(matches, " OR ")))
+	}
+
+	if query.Username != "" {
+		conditions = append(
+			conditions,
+			fmt.Sprintf("username LIKE '%%' || %s || '%%'", arg(query.Username)),
+		)
+	}
+
+	if query.Email != "" && query.IncludeEmail {
+		conditions = append(
+			conditions,
+			fmt.Sprintf("email ILIKE '%%' || %s || '%%'", arg(query.Email)),
+		)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+	}
+
+	total := 0
+	err := s.db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM users %s;", where), args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	direction := "DESC"
+	if query.SortOrder == "asc" {
+		direction = "ASC"
+	}
+
+	orderBy := ""
+	if query.SortBy == "relevance" && keywordParam != "" {
+		similarities := []string{}
+		for _, c := range searchColumns {
+			similarities = append(similarities, fmt.Sprintf("similarity(%s, %s)", c, keywordParam))
+		}
+
+		orderBy = fmt.Sprintf(
+			"GREATEST(%s) %s, id %s",
+			strings.Join(similarities, ", "),
+			direction,
+			direction,
+		)
+	} else {
+		sortColumn := "createdAt"
+		if query.SortBy != "" && query.SortBy != "relevance" {
+			sortColumn = query.SortBy
+		}
+
+		if query.Cursor != "" {
+			cursor, err := utils.DecodeCursor(query.Cursor)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			comparison := "<"
+			if direction == "ASC" {
+				comparison = ">"
+			}
+
+			conditions = append(conditions, fmt.Sprintf(
+				"(%s, id) %s (%s, %s)",
+				sortColumn,
+				comparison,
+				arg(cursor.Value),
+				arg(cursor.Id),
+			))
+			where = fmt.Sprintf("WHERE %s", strings.Join(conditions, " AND "))
+		}
+
+		orderBy = fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)
+	}
+
+	pagination := fmt.Sprintf("LIMIT %s", arg(query.Limit))
+	if query.Cursor == "" {
+		pagination = fmt.Sprintf("%s OFFSET %s", pagination, arg(query.Offset))
+	}
+
 	rows, err := s.db.Query(
-		"SELECT * FROM users WHERE username LIKE $1;",
-		fmt.Sprintf("%%%s%%", query.Username),
+		fmt.Sprintf("SELECT * FROM users %s ORDER BY %s %s;", where, orderBy, pagination),
+		args...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	defer rows.Close()
 
 	users := []types_user.User{}
 
 	for rows.Next() {
 		user, err := scanRow(rows)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		users = append(users, *user)
 	}
 
-	return users, nil
+	return users, total, nil
 }
 
 func (s *Store) GetUserById(id string) (*types_user.User, error) {
@@ -170,6 +283,313 @@ func (s *Store) DeleteUserByUsername(username string) error {
 	return nil
 }
 
+func (s *Store) CreateEmailChange(
+	change types_user.CreateEmailChangePayload,
+) (*types_user.EmailChange, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"UPDATE email_changes SET expiresAt = NOW() WHERE userId = $1 AND confirmedAt IS NULL AND expiresAt > NOW();",
+		change.UserId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rowId := ""
+	err = tx.QueryRow(
+		"INSERT INTO email_changes (userId,oldEmail,newEmail,tokenHash,undoTokenHash,expiresAt,undoExpiresAt) VALUES ($1,$2,$3,$4,$5,$6,$7) RETURNING id;",
+		change.UserId,
+		change.OldEmail,
+		change.NewEmail,
+		change.TokenHash,
+		change.UndoTokenHash,
+		change.ExpiresAt,
+		change.UndoExpiresAt,
+	).Scan(&rowId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getEmailChange("id", rowId)
+}
+
+func (s *Store) GetEmailChangeByTokenHash(tokenHash string) (*types_user.EmailChange, error) {
+	return s.getEmailChange("tokenHash", tokenHash)
+}
+
+func (s *Store) GetEmailChangeByUndoTokenHash(
+	undoTokenHash string,
+) (*types_user.EmailChange, error) {
+	return s.getEmailChange("undoTokenHash", undoTokenHash)
+}
+
+func (s *Store) ConfirmEmailChange(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userId, newEmail := "", ""
+	err = tx.QueryRow(
+		"SELECT userId, newEmail FROM email_changes WHERE id = $1 AND confirmedAt IS NULL AND revertedAt IS NULL FOR UPDATE;",
+		id,
+	).Scan(&userId, &newEmail)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE users SET email = $1 WHERE id = $2;", newEmail, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE email_changes SET confirmedAt = NOW() WHERE id = $1;", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) RevertEmailChange(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	userId, oldEmail, newEmail := "", "", ""
+	var confirmedAt sql.NullTime
+	err = tx.QueryRow(
+		"SELECT userId, oldEmail, newEmail, confirmedAt FROM email_changes WHERE id = $1 AND revertedAt IS NULL FOR UPDATE;",
+		id,
+	).Scan(&userId, &oldEmail, &newEmail, &confirmedAt)
+	if err != nil {
+		return err
+	}
+
+	if confirmedAt.Valid {
+		_, err := tx.Exec(
+			"UPDATE users SET email = $1 WHERE id = $2 AND email = $3;",
+			oldEmail,
+			userId,
+			newEmail,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		"UPDATE email_changes SET revertedAt = NOW(), expiresAt = LEAST(expiresAt, NOW()) WHERE id = $1;",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) ChangeUsername(id string, username string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT INTO username_history (userId,username) SELECT id, username FROM users WHERE id = $1;",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE users SET username = $1 WHERE id = $2;", username, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) GetUsernameHistory(userId string) ([]types_user.UsernameHistory, error) {
+	rows, err := s.db.Query(
+		"SELECT * FROM username_history WHERE userId = $1 ORDER BY changedAt DESC;",
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []types_user.UsernameHistory{}
+
+	for rows.Next() {
+		h := types_user.UsernameHistory{}
+		if err := rows.Scan(&h.Id, &h.UserId, &h.Username, &h.ChangedAt); err != nil {
+			return nil, err
+		}
+
+		history = append(history, h)
+	}
+
+	return history, nil
+}
+
+func (s *Store) GetLatestUsernameHistoryByUsername(
+	username string,
+) (*types_user.UsernameHistory, error) {
+	h := new(types_user.UsernameHistory)
+
+	err := s.db.QueryRow(
+		"SELECT * FROM username_history WHERE username = $1 ORDER BY changedAt DESC LIMIT 1;",
+		username,
+	).Scan(&h.Id, &h.UserId, &h.Username, &h.ChangedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("Username history not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (s *Store) GetPreferences(userId string) (*types_user.Preferences, error) {
+	preferences := types_user.DefaultPreferences()
+
+	raw := []byte{}
+	err := s.db.QueryRow(
+		"SELECT preferences FROM user_preferences WHERE userId = $1;",
+		userId,
+	).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return &preferences, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored documents are decoded over the defaults, so keys missing from
+	// older versions of the document fall back to their default values.
+	if err := json.Unmarshal(raw, &preferences); err != nil {
+		return nil, err
+	}
+
+	preferences.Version = types_user.PreferencesVersion
+
+	return &preferences, nil
+}
+
+func (s *Store) UpdatePreferences(userId string, preferences types_user.Preferences) error {
+	raw, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO user_preferences (userId,version,preferences) VALUES ($1,$2,$3) ON CONFLICT (userId) DO UPDATE SET version = $2, preferences = $3, updatedAt = NOW();",
+		userId,
+		preferences.Version,
+		raw,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) FollowUser(followerId string, followeeId string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO follows (followerId,followeeId) VALUES ($1,$2) ON CONFLICT DO NOTHING;",
+		followerId,
+		followeeId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) UnfollowUser(followerId string, followeeId string) error {
+	_, err := s.db.Exec(
+		"DELETE FROM follows WHERE followerId = $1 AND followeeId = $2;",
+		followerId,
+		followeeId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Store) GetFollowCounts(userId string) (int, int, error) {
+	followers, following := 0, 0
+
+	err := s.db.QueryRow(
+		"SELECT (SELECT COUNT(*) FROM follows WHERE followeeId = $1), (SELECT COUNT(*) FROM follows WHERE followerId = $1);",
+		userId,
+	).Scan(&followers, &following)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return followers, following, nil
+}
+
+func (s *Store) getEmailChange(column string, value string) (*types_user.EmailChange, error) {
+	change := new(types_user.EmailChange)
+	var confirmedAt, revertedAt sql.NullTime
+
+	err := s.db.QueryRow(
+		fmt.Sprintf("SELECT * FROM email_changes WHERE %s = $1;", column),
+		value,
+	).Scan(
+		&change.Id,
+		&change.UserId,
+		&change.OldEmail,
+		&change.NewEmail,
+		&change.TokenHash,
+		&change.UndoTokenHash,
+		&change.ExpiresAt,
+		&change.UndoExpiresAt,
+		&confirmedAt,
+		&revertedAt,
+		&change.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("Email change not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if confirmedAt.Valid {
+		change.ConfirmedAt = &confirmedAt.Time
+	}
+
+	if revertedAt.Valid {
+		change.RevertedAt = &revertedAt.Time
+	}
+
+	return change, nil
+}
+
 func scanRow(rows *sql.Rows) (*types_user.User, error) {
 	user := new(types_user.User)
 
@@ -181,6 +601,7 @@ func scanRow(rows *sql.Rows) (*types_user.User, error) {
 		&user.Email,
 		&user.Password,
 		&user.CreatedAt,
+		&user.Role,
 	)
 	if err != nil {
 		return nil, err
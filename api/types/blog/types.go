@@ -2,45 +2,474 @@ package types_blog
 
 import (
 	"time"
+
+	"github.com/SaeedAlian/megavault/api/utils"
+)
+
+const (
+	BlogVisibilityPublic  = "public"
+	BlogVisibilityMembers = "members"
+)
+
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusScheduled = "scheduled"
+	BlogStatusPublished = "published"
+	BlogStatusArchived  = "archived"
+)
+
+const (
+	BlogReactionLike       = "like"
+	BlogReactionLove       = "love"
+	BlogReactionInsightful = "insightful"
+	BlogReactionFunny      = "funny"
+	BlogReactionCelebrate  = "celebrate"
+)
+
+const (
+	BlogAuthorRoleOwner    = "owner"
+	BlogAuthorRoleCoAuthor = "coauthor"
+	BlogAuthorRoleReviewer = "reviewer"
+)
+
+const (
+	BlogInvitationStatusPending  = "pending"
+	BlogInvitationStatusAccepted = "accepted"
+	BlogInvitationStatusDeclined = "declined"
+)
+
+const (
+	ImportFormatHugo      = "hugo"
+	ImportFormatJekyll    = "jekyll"
+	ImportFormatMarkdown  = "markdown"
+	ImportFormatWordPress = "wordpress"
+)
+
+const (
+	ImportResultImported = "imported"
+	ImportResultSkipped  = "skipped"
+	ImportResultFailed   = "failed"
 )
 
 type BlogStore interface {
 	CreateBlog(blog CreateBlogPayload) (*Blog, error)
-	GetBlogs(query SearchBlogQuery) ([]Blog, error)
+	GetBlogs(query SearchBlogQuery) ([]Blog, int, error)
 	GetBlogById(id string) (*Blog, error)
 	GetBlogBySlug(slug string) (*Blog, error)
+	GetBlogByOldSlug(slug string) (*Blog, error)
+	GetBlogContent(id string) (*string, error)
 	UpdateBlog(id string, blog UpdateBlogPayload) error
 	DeleteBlogById(id string) error
+	GetFeed(query FeedQuery) ([]Blog, error)
+	UpdateBlogStatus(id string, status UpdateBlogStatusPayload) error
+	PublishScheduledBlogs(now time.Time) (int64, error)
+	UnpublishExpiredBlogs(now time.Time) (int64, error)
+	GetBlogRevisions(blogId string) ([]BlogRevision, error)
+	GetBlogRevision(blogId string, revision int) (*BlogRevision, error)
+	GetTags() ([]Tag, error)
+	GetTagBySlug(slug string) (*Tag, error)
+	RenameTag(id string, name string, slug string) error
+	MergeTags(sourceId string, targetId string) error
+	GetCategories() ([]Category, error)
+	GetCategoryById(id string) (*Category, error)
+	GetCategoryBySlug(slug string) (*Category, error)
+	CreateCategory(category CreateCategoryPayload) (*Category, error)
+	ToggleReaction(blogId string, userId string, reaction string) (bool, error)
+	GetReactionCounts(blogId string) (map[string]int, error)
+	RecordViews(views []BlogView) (int64, error)
+	GetBlogFiles() ([]BlogFiles, error)
+	GetRevisionFiles() ([]BlogFiles, error)
+	GetContentImageNames() ([]string, error)
+	CountSitemapEntries() (int, error)
+	GetSitemapEntries(offset int, limit int) ([]SitemapEntry, error)
+	GetRelatedBlogs(blogId string, limit int) ([]Blog, error)
+	GetSeries() ([]Series, error)
+	GetSeriesById(id string) (*Series, error)
+	GetSeriesBySlug(slug string) (*Series, error)
+	GetSeriesByBlogId(blogId string) (*Series, error)
+	GetSeriesParts(seriesId string) ([]Blog, error)
+	CreateSeries(series CreateSeriesPayload) (*Series, error)
+	UpdateSeries(id string, series UpdateSeriesPayload) error
+	DeleteSeries(id string) error
+	AddSeriesPart(seriesId string, blogId string, position int) error
+	RemoveSeriesPart(seriesId string, blogId string) error
+	ReorderSeries(seriesId string, blogIds []string) error
+	CreateBlogInvitation(invitation InviteBlogAuthorPayload) (*BlogInvitation, error)
+	GetBlogInvitationById(id string) (*BlogInvitation, error)
+	GetPendingBlogInvitations(userId string) ([]BlogInvitation, error)
+	RespondToBlogInvitation(id string, accepted bool) error
+	RemoveBlogAuthor(blogId string, userId string) error
+	UpdateBlogStats(id string, stats ReadingStats) error
 }
 
 type Blog struct {
-	Id          string    `json:"id"`
+	Id          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Slug        string            `json:"slug"`
+	PictureName string            `json:"pictureName"`
+	MDFilename  string            `json:"mdFilename"`
+	AuthorId    string            `json:"authorId"`
+	Visibility  string            `json:"visibility"`
+	Status      string            `json:"status"`
+	PublishedAt *time.Time        `json:"publishedAt"`
+	ScheduledAt *time.Time        `json:"scheduledAt"`
+	ExpiresAt   *time.Time        `json:"expiresAt"`
+	CategoryId  string            `json:"categoryId"`
+	Authors     []BlogAuthor      `json:"authors"`
+	Tags        []Tag             `json:"tags"`
+	Reactions   map[string]int    `json:"reactions"`
+	Stats       ReadingStats      `json:"stats"`
+	ViewCount   int64             `json:"viewCount"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+	Rank        float64           `json:"rank,omitempty"`
+	Snippet     string            `json:"snippet,omitempty"`
+	Series      *SeriesNavigation `json:"series,omitempty"`
+}
+
+type CreateBlogPayload struct {
+	Slug        string       `json:"slug"`
+	Title       string       `json:"title"       validate:"required"`
+	Description string       `json:"description" validate:"required"`
+	PictureName string       `json:"pictureName" validate:"required"`
+	MDFilename  string       `json:"mdFilename"  validate:"required"`
+	Visibility  string       `json:"visibility"  validate:"omitempty,oneof=public members"`
+	Status      string       `json:"status"      validate:"omitempty,oneof=draft scheduled published"`
+	ScheduledAt *time.Time   `json:"scheduledAt" validate:"required_if=Status scheduled"`
+	ExpiresAt   *time.Time   `json:"expiresAt"`
+	CategoryId  string       `json:"categoryId"  validate:"omitempty,uuid"`
+	Tags        []string     `json:"tags"        validate:"omitempty,max=20,dive,required,max=63"`
+	PublishedAt *time.Time   `json:"-"`
+	AuthorId    string       `json:"-"`
+	Content     string       `json:"-"`
+	Stats       ReadingStats `json:"-"`
+	CreatedAt   *time.Time   `json:"-"`
+}
+
+type PublishBlogPayload struct {
+	ScheduledAt *time.Time `json:"scheduledAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+type UpdateBlogStatusPayload struct {
+	Status      string
+	PublishedAt *time.Time
+	ScheduledAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+type UpdateBlogPayload struct {
+	Slug        string    `json:"slug"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
-	Slug        string    `json:"slug"`
 	PictureName string    `json:"pictureName"`
 	MDFilename  string    `json:"mdFilename"`
-	CreatedAt   time.Time `json:"createdAt"`
+	Visibility  string    `json:"visibility"  validate:"omitempty,oneof=public members"`
+	CategoryId  string    `json:"categoryId"  validate:"omitempty,uuid"`
+	Tags        []string  `json:"tags"        validate:"omitempty,max=20,dive,required,max=63"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	// Content is nil when the markdown file of the blog can't be read, which
+	// clears the stored content instead of replacing it with an empty one.
+	Content *string `json:"-"`
+	// PreviousContent is the markdown of the blog before this update, used to
+	// snapshot a baseline revision for blogs created before revisions existed.
+	PreviousContent string       `json:"-"`
+	EditorId        string       `json:"-"`
+	Stats           ReadingStats `json:"-"`
 }
 
-type CreateBlogPayload struct {
-	Slug        string `json:"slug"`
-	Title       string `json:"title"       validate:"required"`
-	Description string `json:"description" validate:"required"`
-	PictureName string `json:"pictureName" validate:"required"`
-	MDFilename  string `json:"mdFilename"  validate:"required"`
+type SearchBlogQuery struct {
+	Keyword    string `json:"keyword"`
+	Status     string `json:"status"    validate:"omitempty,oneof=draft scheduled published archived"`
+	Tag        string `json:"tag"`
+	Category   string `json:"category"`
+	AuthorId   string `json:"-"`
+	PublicOnly bool   `json:"-"`
+	SortBy     string `json:"sortBy"    validate:"omitempty,oneof=createdAt updatedAt publishedAt title relevance"`
+	SortOrder  string `json:"sortOrder" validate:"omitempty,oneof=asc desc"`
+	Page       int    `json:"page"      validate:"min=1"`
+	Limit      int    `json:"limit"     validate:"min=1,max=100"`
+	Cursor     string `json:"cursor"`
 }
 
-type UpdateBlogPayload struct {
-	Slug        string    `json:"slug"`
+type BlogSearchResult struct {
+	Result     []Blog `json:"result"`
+	Total      int    `json:"total"`
+	Page       int    `json:"page"`
+	PageCount  int    `json:"pageCount"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type FeedQuery struct {
+	UserId string `json:"-"`
+	Limit  int    `json:"limit"  validate:"min=1,max=100"`
+	Cursor string `json:"cursor"`
+}
+
+type FeedResult struct {
+	Result     []Blog `json:"result"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type BlogRevision struct {
+	Id          string    `json:"id"`
+	BlogId      string    `json:"blogId"`
+	Revision    int       `json:"revision"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
+	Slug        string    `json:"slug"`
 	PictureName string    `json:"pictureName"`
 	MDFilename  string    `json:"mdFilename"`
+	Content     string    `json:"content,omitempty"`
+	EditorId    string    `json:"editorId"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type BlogFieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type BlogRevisionDiff struct {
+	From    int                        `json:"from"`
+	To      int                        `json:"to"`
+	Mode    string                     `json:"mode"`
+	Fields  map[string]BlogFieldChange `json:"fields"`
+	Unified string                     `json:"unified,omitempty"`
+	Words   []utils.DiffOp             `json:"words,omitempty"`
+}
+
+type Tag struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int    `json:"postCount,omitempty"`
+}
+
+type RenameTagPayload struct {
+	Name string `json:"name" validate:"required,max=63"`
+}
+
+type MergeTagPayload struct {
+	Into string `json:"into" validate:"required"`
+}
+
+type Category struct {
+	Id        string     `json:"id"`
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentId  string     `json:"parentId"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Children  []Category `json:"children,omitempty"`
+}
+
+type CreateCategoryPayload struct {
+	Name     string `json:"name"     validate:"required,max=255"`
+	ParentId string `json:"parentId" validate:"omitempty,uuid"`
+	Slug     string `json:"-"`
+}
+
+type Series struct {
+	Id          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	AuthorId    string    `json:"authorId"`
+	PartCount   int       `json:"partCount"`
+	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Parts       []Blog    `json:"parts,omitempty"`
 }
 
-type SearchBlogQuery struct {
-	Keyword string `json:"keyword"`
+type SeriesPart struct {
+	Id       string `json:"id"`
+	Title    string `json:"title"`
+	Slug     string `json:"slug"`
+	Position int    `json:"position"`
+}
+
+type SeriesNavigation struct {
+	Id       string      `json:"id"`
+	Title    string      `json:"title"`
+	Slug     string      `json:"slug"`
+	Position int         `json:"position"`
+	Total    int         `json:"total"`
+	Previous *SeriesPart `json:"previous"`
+	Next     *SeriesPart `json:"next"`
+}
+
+type CreateSeriesPayload struct {
+	Title       string `json:"title"       validate:"required,max=255"`
+	Description string `json:"description"`
+	Slug        string `json:"-"`
+	AuthorId    string `json:"-"`
+}
+
+type UpdateSeriesPayload struct {
+	Title       string `json:"title"       validate:"omitempty,max=255"`
+	Description string `json:"description"`
+	Slug        string `json:"-"`
+}
+
+type AddSeriesPartPayload struct {
+	BlogId   string `json:"blogId"   validate:"required"`
+	Position int    `json:"position" validate:"min=0"`
+}
+
+type MoveSeriesPartPayload struct {
+	Position int `json:"position" validate:"required,min=1"`
+}
+
+type ReorderSeriesPayload struct {
+	BlogIds []string `json:"blogIds" validate:"required,min=1,dive,required"`
+}
+
+type BlogAuthor struct {
+	UserId   string `json:"userId"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type BlogInvitation struct {
+	Id          string     `json:"id"`
+	BlogId      string     `json:"blogId"`
+	BlogTitle   string     `json:"blogTitle"`
+	InviterId   string     `json:"inviterId"`
+	InviteeId   string     `json:"inviteeId"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+}
+
+type InviteBlogAuthorPayload struct {
+	Username  string `json:"username" validate:"required"`
+	Role      string `json:"role"     validate:"required,oneof=coauthor reviewer"`
+	BlogId    string `json:"-"`
+	InviterId string `json:"-"`
+	InviteeId string `json:"-"`
+}
+
+type ReactionPayload struct {
+	Reaction string `json:"reaction" validate:"required,oneof=like love insightful funny celebrate"`
+}
+
+type ReactionResult struct {
+	Reaction  string         `json:"reaction"`
+	Active    bool           `json:"active"`
+	Reactions map[string]int `json:"reactions"`
+}
+
+type BlogView struct {
+	BlogId    string
+	VisitorId string
+	Day       string
+}
+
+type FrontMatter struct {
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	CoverImage  string     `json:"coverImage,omitempty"`
+	PublishDate *time.Time `json:"publishDate,omitempty"`
+	Slug        string     `json:"slug,omitempty"`
+}
+
+type MdUploadResult struct {
+	Message     string       `json:"message"`
+	Filename    string       `json:"filename"`
+	FrontMatter *FrontMatter `json:"frontMatter"`
+	Stats       ReadingStats `json:"stats"`
+}
+
+// ReadingStats are derived from the markdown of a blog whenever it changes.
+// ReadingTime is in minutes.
+type ReadingStats struct {
+	WordCount      int `json:"wordCount"`
+	ReadingTime    int `json:"readingTime"`
+	HeadingCount   int `json:"headingCount"`
+	ImageCount     int `json:"imageCount"`
+	CodeBlockCount int `json:"codeBlockCount"`
+}
+
+type BlogContent struct {
+	Format  string           `json:"format"`
+	Content string           `json:"content"`
+	Toc     []utils.TocEntry `json:"toc"`
+}
+
+type BlogFiles struct {
+	BlogId      string
+	Slug        string
+	MDFilename  string
+	PictureName string
+}
+
+type MissingFile struct {
+	BlogId   string `json:"blogId"`
+	Slug     string `json:"slug"`
+	Kind     string `json:"kind"`
+	Filename string `json:"filename"`
+}
+
+type OrphanFile struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modifiedAt"`
+	Pending    bool      `json:"pending"`
+	Removed    bool      `json:"removed"`
+}
+
+type FsckReport struct {
+	CheckedBlogs  int           `json:"checkedBlogs"`
+	MissingFiles  []MissingFile `json:"missingFiles"`
+	OrphanFiles   []OrphanFile  `json:"orphanFiles"`
+	PendingFiles  int           `json:"pendingFiles"`
+	RemovedFiles  int           `json:"removedFiles"`
+	ReclaimedSize int64         `json:"reclaimedSize"`
+	DryRun        bool          `json:"dryRun"`
+}
+
+type ImportPost struct {
+	Source      string     `json:"source"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	Tags        []string   `json:"tags"`
+	Images      []string   `json:"images"`
+	Warnings    []string   `json:"warnings"`
+	Result      string     `json:"result"`
+	Error       string     `json:"error,omitempty"`
+	BlogId      string     `json:"blogId,omitempty"`
+}
+
+type ImportReport struct {
+	Format   string       `json:"format"`
+	DryRun   bool         `json:"dryRun"`
+	Imported int          `json:"imported"`
+	Skipped  int          `json:"skipped"`
+	Failed   int          `json:"failed"`
+	Posts    []ImportPost `json:"posts"`
+}
+
+type SitemapEntry struct {
+	Slug      string
+	UpdatedAt time.Time
+}
+
+type BlogMeta struct {
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	URL           string            `json:"url"`
+	Image         string            `json:"image"`
+	Type          string            `json:"type"`
+	SiteName      string            `json:"siteName"`
+	Author        string            `json:"author"`
+	Tags          []string          `json:"tags"`
+	PublishedTime *time.Time        `json:"publishedTime"`
+	ModifiedTime  time.Time         `json:"modifiedTime"`
+	OpenGraph     map[string]string `json:"openGraph"`
+	Twitter       map[string]string `json:"twitter"`
 }
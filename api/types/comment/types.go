@@ -0,0 +1,54 @@
+package types_comment
+
+import (
+	"time"
+)
+
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+)
+
+type CommentStore interface {
+	CreateComment(comment CreateCommentPayload) (*Comment, error)
+	GetCommentById(id string) (*Comment, error)
+	GetCommentsByBlogId(blogId string, includeUnapprovedOf string) ([]Comment, error)
+	GetPendingComments() ([]Comment, error)
+	UpdateComment(id string, body string, editedAt time.Time) error
+	DeleteComment(id string, deletedAt time.Time) error
+	UpdateCommentStatus(id string, status string) error
+	CountApprovedCommentsByAuthor(authorId string) (int, error)
+	GetCommentsEnabled(blogId string) (bool, error)
+	SetCommentsEnabled(blogId string, enabled bool) error
+}
+
+type Comment struct {
+	Id        string     `json:"id"`
+	BlogId    string     `json:"blogId"`
+	ParentId  string     `json:"parentId"`
+	AuthorId  string     `json:"authorId"`
+	Body      string     `json:"body"`
+	BodyHTML  string     `json:"bodyHtml"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	EditedAt  *time.Time `json:"editedAt"`
+	DeletedAt *time.Time `json:"deletedAt"`
+	Replies   []Comment  `json:"replies,omitempty"`
+}
+
+type CreateCommentPayload struct {
+	Body     string `json:"body"     validate:"required,max=5000"`
+	ParentId string `json:"parentId" validate:"omitempty,uuid"`
+	BlogId   string `json:"-"`
+	AuthorId string `json:"-"`
+	Status   string `json:"-"`
+}
+
+type UpdateCommentPayload struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type CommentSettingsPayload struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
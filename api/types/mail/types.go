@@ -0,0 +1,11 @@
+package types_mail
+
+type Mailer interface {
+	Send(mail Mail) error
+}
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -8,25 +8,50 @@ import (
 
 type UserStore interface {
 	CreateUser(user RegisterUserPayload) (*User, error)
-	GetUsers(query SearchUserQuery) ([]User, error)
+	GetUsers(query SearchUserQuery) ([]User, int, error)
 	GetUserById(id string) (*User, error)
 	GetUserByUsername(username string) (*User, error)
 	GetUserByEmail(email string) (*User, error)
 	GetUserByUsernameOrEmail(username string, email string) (*User, error)
 	DeleteUserById(id string) error
 	DeleteUserByUsername(username string) error
+	CreateEmailChange(change CreateEmailChangePayload) (*EmailChange, error)
+	GetEmailChangeByTokenHash(tokenHash string) (*EmailChange, error)
+	GetEmailChangeByUndoTokenHash(undoTokenHash string) (*EmailChange, error)
+	ConfirmEmailChange(id string) error
+	RevertEmailChange(id string) error
+	ChangeUsername(id string, username string) error
+	GetUsernameHistory(userId string) ([]UsernameHistory, error)
+	GetLatestUsernameHistoryByUsername(username string) (*UsernameHistory, error)
+	GetPreferences(userId string) (*Preferences, error)
+	UpdatePreferences(userId string, preferences Preferences) error
+	FollowUser(followerId string, followeeId string) error
+	UnfollowUser(followerId string, followeeId string) error
+	GetFollowCounts(userId string) (int, int, error)
 }
 
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type User struct {
 	Id        string    `json:"id"`
 	FirstName string    `json:"firstname"`
 	LastName  string    `json:"lastname"`
-	Email     string    `json:"email"`
+	Email     string    `json:"email,omitempty"`
 	Username  string    `json:"username"`
 	Password  string    `json:"-"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+type Profile struct {
+	User
+	FollowersCount int `json:"followersCount"`
+	FollowingCount int `json:"followingCount"`
+}
+
 type LoginUserPayload struct {
 	UsernameOrEmail string `json:"usernameOrEmail" validate:"required"`
 	Password        string `json:"password"        validate:"required,min=6,max=130"`
@@ -41,7 +66,111 @@ type RegisterUserPayload struct {
 }
 
 type SearchUserQuery struct {
-	Username string `json:"username"`
+	Keyword      string `json:"keyword"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	IncludeEmail bool   `json:"-"`
+	Fuzzy        bool   `json:"fuzzy"`
+	SortBy       string `json:"sortBy"    validate:"omitempty,oneof=username firstname lastname createdAt relevance"`
+	SortOrder    string `json:"sortOrder" validate:"omitempty,oneof=asc desc"`
+	Limit        int    `json:"limit"     validate:"min=1,max=100"`
+	Offset       int    `json:"offset"    validate:"min=0"`
+	Cursor       string `json:"cursor"`
+}
+
+type UserSearchResult struct {
+	Result     []User `json:"result"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type EmailChange struct {
+	Id            string     `json:"id"`
+	UserId        string     `json:"userId"`
+	OldEmail      string     `json:"oldEmail"`
+	NewEmail      string     `json:"newEmail"`
+	TokenHash     string     `json:"-"`
+	UndoTokenHash string     `json:"-"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	UndoExpiresAt time.Time  `json:"undoExpiresAt"`
+	ConfirmedAt   *time.Time `json:"confirmedAt"`
+	RevertedAt    *time.Time `json:"revertedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type ChangeEmailPayload struct {
+	Email    string `json:"email"    validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type CreateEmailChangePayload struct {
+	UserId        string
+	OldEmail      string
+	NewEmail      string
+	TokenHash     string
+	UndoTokenHash string
+	ExpiresAt     time.Time
+	UndoExpiresAt time.Time
+}
+
+type UsernameHistory struct {
+	Id        string    `json:"id"`
+	UserId    string    `json:"userId"`
+	Username  string    `json:"username"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+type ChangeUsernamePayload struct {
+	Username string `json:"username" validate:"required,min=3,max=30,username"`
+	Password string `json:"password" validate:"required"`
+}
+
+const PreferencesVersion = 1
+
+type Preferences struct {
+	Version       int                     `json:"version"`
+	Theme         string                  `json:"theme"         validate:"oneof=system light dark"`
+	Language      string                  `json:"language"      validate:"bcp47_language_tag"`
+	Notifications NotificationPreferences `json:"notifications"`
+	Editor        EditorPreferences       `json:"editor"`
+}
+
+type NotificationPreferences struct {
+	NewFollower  bool `json:"newFollower"`
+	NewComment   bool `json:"newComment"`
+	CommentReply bool `json:"commentReply"`
+	Newsletter   bool `json:"newsletter"`
+}
+
+type EditorPreferences struct {
+	FontSize    int    `json:"fontSize"    validate:"min=10,max=32"`
+	TabSize     int    `json:"tabSize"     validate:"min=2,max=8"`
+	LineWrap    bool   `json:"lineWrap"`
+	LineNumbers bool   `json:"lineNumbers"`
+	Preview     string `json:"preview"     validate:"oneof=none side tab"`
+}
+
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Version:  PreferencesVersion,
+		Theme:    "system",
+		Language: "en",
+		Notifications: NotificationPreferences{
+			NewFollower:  true,
+			NewComment:   true,
+			CommentReply: true,
+			Newsletter:   false,
+		},
+		Editor: EditorPreferences{
+			FontSize:    14,
+			TabSize:     2,
+			LineWrap:    true,
+			LineNumbers: false,
+			Preview:     "side",
+		},
+	}
 }
 
 type UserJWTClaims struct {
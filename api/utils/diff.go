@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type diffEdit struct {
+	op   string
+	text string
+	aPos int
+	bPos int
+}
+
+// maxDiffTokens bounds the lines or words that differ between the two texts,
+// which keeps a diff of two large and unrelated revisions from tying up the
+// server.
+const maxDiffTokens = 20000
+
+var ErrDiffTooLarge = fmt.Errorf("The texts are too different to be compared")
+
+var wordDiffTokenRegex = regexp.MustCompile(`\s+|[^\s]+`)
+
+func UnifiedDiff(
+	a string,
+	b string,
+	fromName string,
+	toName string,
+	context int,
+) (string, error) {
+	edits, err := diffTokens(splitLines(a), splitLines(b))
+	if err != nil {
+		return "", err
+	}
+
+	changed := []int{}
+	for i, e := range edits {
+		if e.op != DiffEqual {
+			changed = append(changed, i)
+		}
+	}
+
+	if len(changed) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for i := 0; i < len(changed); {
+		start := max(changed[i]-context, 0)
+		end := min(changed[i]+context+1, len(edits))
+
+		j := i + 1
+		for j < len(changed) && changed[j]-context <= end {
+			end = min(changed[j]+context+1, len(edits))
+			j++
+		}
+
+		aLen, bLen := 0, 0
+		for _, e := range edits[start:end] {
+			if e.op != DiffInsert {
+				aLen++
+			}
+			if e.op != DiffDelete {
+				bLen++
+			}
+		}
+
+		aStart, bStart := edits[start].aPos, edits[start].bPos
+		if aLen > 0 {
+			aStart++
+		}
+		if bLen > 0 {
+			bStart++
+		}
+
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart, aLen, bStart, bLen))
+
+		for _, e := range edits[start:end] {
+			prefix := " "
+			switch e.op {
+			case DiffInsert:
+				prefix = "+"
+			case DiffDelete:
+				prefix = "-"
+			}
+
+			sb.WriteString(prefix)
+			sb.WriteString(strings.TrimSuffix(e.text, "\n"))
+			sb.WriteString("\n")
+		}
+
+		i = j
+	}
+
+	return sb.String(), nil
+}
+
+func WordDiff(a string, b string) ([]DiffOp, error) {
+	edits, err := diffTokens(
+		wordDiffTokenRegex.FindAllString(a, -1),
+		wordDiffTokenRegex.FindAllString(b, -1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := []DiffOp{}
+	for _, e := range edits {
+		if len(ops) > 0 && ops[len(ops)-1].Op == e.op {
+			ops[len(ops)-1].Text += e.text
+			continue
+		}
+
+		ops = append(ops, DiffOp{Op: e.op, Text: e.text})
+	}
+
+	return ops, nil
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// diffTokens finds the shortest edit script between a and b using the
+// linear space variant of the Myers algorithm: the middle snake of an optimal
+// path splits the problem in two halves, which are solved recursively. The
+// running time still grows with the product of the lengths and the number of
+// edits, so inputs that remain too long once their common prefix and suffix
+// are left out are refused.
+func diffTokens(a []string, b []string) ([]diffEdit, error) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-suffix-1] == b[len(b)-suffix-1] {
+		suffix++
+	}
+
+	if len(a)+len(b)-2*(prefix+suffix) > maxDiffTokens {
+		return nil, ErrDiffTooLarge
+	}
+
+	d := &differ{a: a, b: b, edits: []diffEdit{}}
+	d.compare(0, len(a), 0, len(b))
+
+	return d.edits, nil
+}
+
+type differ struct {
+	a     []string
+	b     []string
+	edits []diffEdit
+}
+
+func (d *differ) equal(x int, y int) {
+	d.edits = append(d.edits, diffEdit{op: DiffEqual, text: d.a[x], aPos: x, bPos: y})
+}
+
+func (d *differ) compare(aLo int, aHi int, bLo int, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.equal(aLo, bLo)
+		aLo++
+		bLo++
+	}
+
+	suffix := 0
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+		suffix++
+	}
+
+	x, y, ok := -1, -1, false
+	if aLo < aHi && bLo < bHi {
+		x, y, ok = d.middleSnake(aLo, aHi, bLo, bHi)
+	}
+
+	if ok {
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	} else {
+		for i := aLo; i < aHi; i++ {
+			d.edits = append(d.edits, diffEdit{op: DiffDelete, text: d.a[i], aPos: i, bPos: bLo})
+		}
+
+		for j := bLo; j < bHi; j++ {
+			d.edits = append(d.edits, diffEdit{op: DiffInsert, text: d.b[j], aPos: aHi, bPos: j})
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.equal(aHi+i, bHi+i)
+	}
+}
+
+// middleSnake walks forward from the start and backward from the end of both
+// ranges at the same time, and returns the point where the two paths meet.
+// Diagonals whose paths leave the ranges are no longer extended. It reports
+// false when the ranges have nothing in common.
+func (d *differ) middleSnake(aLo int, aHi int, bLo int, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	delta := n - m
+	odd := delta%2 != 0
+
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	forwardStart, forwardEnd, backwardStart, backwardEnd := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k := -step + forwardStart; k <= step-forwardEnd; k += 2 {
+			i := offset + k
+
+			x := 0
+			if k == -step || (k != step && forward[i-1] < forward[i+1]) {
+				x = forward[i+1]
+			} else {
+				x = forward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[i] = x
+
+			switch {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd:
+				j := offset + delta - k
+				if j >= 0 && j < size && backward[j] != -1 && x >= n-backward[j] {
+					return d.split(aLo, aHi, bLo, bHi, aLo+x, bLo+y)
+				}
+			}
+		}
+
+		for k := -step + backwardStart; k <= step-backwardEnd; k += 2 {
+			i := offset + k
+
+			x := 0
+			if k == -step || (k != step && backward[i-1] < backward[i+1]) {
+				x = backward[i+1]
+			} else {
+				x = backward[i-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[i] = x
+
+			switch {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd:
+				j := offset + delta - k
+				if j >= 0 && j < size && forward[j] != -1 && forward[j] >= n-x {
+					fx := forward[j]
+					return d.split(aLo, aHi, bLo, bHi, aLo+fx, bLo+fx-(j-offset))
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// split refuses points that wouldn't make the problem smaller, so compare
+// always terminates.
+func (d *differ) split(aLo int, aHi int, bLo int, bHi int, x int, y int) (int, int, bool) {
+	if (x == aLo && y == bLo) || (x == aHi && y == bHi) {
+		return 0, 0, false
+	}
+
+	return x, y, true
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "# Title\n\nfirst line\nsecond line\nthird line\n"
+	b := "# Title\n\nfirst line\nchanged line\nthird line\nfourth line\n"
+
+	diff, err := UnifiedDiff(a, b, "rev1", "rev2", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := strings.Join([]string{
+		"--- rev1",
+		"+++ rev2",
+		"@@ -3,3 +3,4 @@",
+		" first line",
+		"-second line",
+		"+changed line",
+		" third line",
+		"+fourth line",
+		"",
+	}, "\n")
+
+	if diff != expected {
+		t.Errorf("Unexpected unified diff, expected:\n%s\nreceived:\n%s", expected, diff)
+	}
+}
+
+func TestUnifiedDiffWithoutChanges(t *testing.T) {
+	if diff, _ := UnifiedDiff("same\n", "same\n", "a", "b", 3); diff != "" {
+		t.Errorf("Expected an empty diff, received %q", diff)
+	}
+}
+
+func TestWordDiff(t *testing.T) {
+	ops, err := WordDiff("the quick brown fox", "the slow brown fox jumps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DiffOp{
+		{Op: DiffEqual, Text: "the "},
+		{Op: DiffDelete, Text: "quick"},
+		{Op: DiffInsert, Text: "slow"},
+		{Op: DiffEqual, Text: " brown fox"},
+		{Op: DiffInsert, Text: " jumps"},
+	}
+
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d operations, received %v", len(expected), ops)
+	}
+
+	for i := range expected {
+		if ops[i] != expected[i] {
+			t.Errorf("Expected operation %v at %d, received %v", expected[i], i, ops[i])
+		}
+	}
+}
+
+func TestWordDiffTooLarge(t *testing.T) {
+	a := strings.Repeat("a ", maxDiffTokens)
+	b := strings.Repeat("b ", maxDiffTokens)
+
+	if _, err := WordDiff(a, b); err != ErrDiffTooLarge {
+		t.Errorf("Expected %v, received %v", ErrDiffTooLarge, err)
+	}
+
+	if _, err := WordDiff(a+"x", a+"y"); err != nil {
+		t.Errorf("Expected texts with a long common prefix to be compared, received %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFrontMatter splits a markdown document into its front matter and body.
+// YAML front matter is delimited by "---" lines and TOML front matter by "+++"
+// lines. Scalars are returned as strings, lists of scalars as []string, and
+// nested tables as map[string]any holding values of the same kinds. A document
+// without front matter returns a nil map.
+func ParseFrontMatter(content string) (map[string]any, string, error) {
+	content = strings.TrimPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "\uFEFF")
+
+	delimiter := ""
+	switch {
+	case strings.HasPrefix(content, "---\n"):
+		delimiter = "---"
+	case strings.HasPrefix(content, "+++\n"):
+		delimiter = "+++"
+	default:
+		return nil, content, nil
+	}
+
+	rest := content[len(delimiter)+1:]
+
+	end := -1
+	if strings.HasPrefix(rest, delimiter+"\n") || rest == delimiter {
+		end = 0
+	} else if i := strings.Index(rest, "\n"+delimiter+"\n"); i != -1 {
+		end = i + 1
+	} else if strings.HasSuffix(rest, "\n"+delimiter) {
+		end = len(rest) - len(delimiter)
+	}
+
+	if end == -1 {
+		return nil, content, fmt.Errorf("Front matter is not closed")
+	}
+
+	block := rest[:end]
+	body := strings.TrimPrefix(rest[end+len(delimiter):], "\n")
+
+	metadata := map[string]any{}
+
+	if delimiter == "---" {
+		if err := yaml.Unmarshal([]byte(block), &metadata); err != nil {
+			return nil, content, fmt.Errorf("Invalid YAML front matter: %v", err)
+		}
+	} else {
+		if _, err := toml.Decode(block, &metadata); err != nil {
+			return nil, content, fmt.Errorf("Invalid TOML front matter: %v", err)
+		}
+	}
+
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	return normalizeFrontMatter(metadata), body, nil
+}
+
+func normalizeFrontMatter(metadata map[string]any) map[string]any {
+	normalized := make(map[string]any, len(metadata))
+
+	for k, v := range metadata {
+		normalized[k] = normalizeFrontMatterValue(v)
+	}
+
+	return normalized
+}
+
+func normalizeFrontMatterValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return v
+	case time.Time:
+		// TOML dates and times without an offset are decoded into these zones.
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05")
+		case "time-local":
+			return v.Format(time.TimeOnly)
+		}
+
+		return v.Format(time.RFC3339)
+	case map[string]any:
+		return normalizeFrontMatter(v)
+	case []map[string]any:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = normalizeFrontMatter(item)
+		}
+
+		return list
+	case []any:
+		list := make([]any, len(v))
+		scalars := make([]string, 0, len(v))
+
+		for i, item := range v {
+			list[i] = normalizeFrontMatterValue(item)
+			if s, ok := list[i].(string); ok {
+				scalars = append(scalars, s)
+			}
+		}
+
+		if len(scalars) == len(list) {
+			return scalars
+		}
+
+		return list
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatterYAML(t *testing.T) {
+	content := "---\n" +
+		"title: \"Hello: World\"\n" +
+		"description: 'It''s here' # a comment\n" +
+		"tags:\n" +
+		"  - go\n" +
+		"  - \"web dev\"\n" +
+		"categories: [one, \"two, three\"]\n" +
+		"---\n" +
+		"# Body\n"
+
+	metadata, body, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"title":       "Hello: World",
+		"description": "It's here",
+		"tags":        []string{"go", "web dev"},
+		"categories":  []string{"one", "two, three"},
+	}
+
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Unexpected metadata, expected %v, received %v", expected, metadata)
+	}
+
+	if body != "# Body\n" {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestParseFrontMatterTOML(t *testing.T) {
+	content := "+++\r\n" +
+		"title = \"Hello\"\r\n" +
+		"date = 2024-01-02T10:00:00Z\r\n" +
+		"tags = [\"a\", \"b\"]\r\n" +
+		"+++\r\n" +
+		"Body"
+
+	metadata, body, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"title": "Hello",
+		"date":  "2024-01-02T10:00:00Z",
+		"tags":  []string{"a", "b"},
+	}
+
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Unexpected metadata, expected %v, received %v", expected, metadata)
+	}
+
+	if body != "Body" {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestParseFrontMatterWithoutMetadata(t *testing.T) {
+	metadata, body, err := ParseFrontMatter("# Title\n\n---\n")
+	if err != nil || metadata != nil || body != "# Title\n\n---\n" {
+		t.Errorf("Expected the content to be returned untouched")
+	}
+
+	if _, _, err := ParseFrontMatter("---\ntitle: a\n"); err == nil {
+		t.Errorf("Expected an error for unclosed front matter")
+	}
+}
+
+func TestParseFrontMatterNestedYAML(t *testing.T) {
+	content := "---\n" +
+		"title: Nested\n" +
+		"date: 2020-05-01 10:00:00 +0000\n" +
+		"draft: true\n" +
+		"description: |\n" +
+		"  First line\n" +
+		"  second line\n" +
+		"author:\n" +
+		"  name: Jane\n" +
+		"  links:\n" +
+		"    - https://example.com\n" +
+		"---\n" +
+		"Body"
+
+	metadata, _, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"title":       "Nested",
+		"date":        "2020-05-01 10:00:00 +0000",
+		"draft":       "true",
+		"description": "First line\nsecond line\n",
+		"author": map[string]any{
+			"name":  "Jane",
+			"links": []string{"https://example.com"},
+		},
+	}
+
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Unexpected metadata, expected %v, received %v", expected, metadata)
+	}
+}
+
+func TestParseFrontMatterTOMLTables(t *testing.T) {
+	content := "+++\n" +
+		"title = \"Tables\"\n" +
+		"date = 2024-01-02\n" +
+		"tags = [\n" +
+		"  \"a\",\n" +
+		"  \"b\",\n" +
+		"]\n" +
+		"\n" +
+		"[params]\n" +
+		"cover = \"/images/cover.png\"\n" +
+		"+++\n" +
+		"Body"
+
+	metadata, _, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"title":  "Tables",
+		"date":   "2024-01-02",
+		"tags":   []string{"a", "b"},
+		"params": map[string]any{"cover": "/images/cover.png"},
+	}
+
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("Unexpected metadata, expected %v, received %v", expected, metadata)
+	}
+
+	if _, _, err := ParseFrontMatter("+++\ntitle = \n+++\n"); err == nil {
+		t.Errorf("Expected an error for invalid TOML")
+	}
+}
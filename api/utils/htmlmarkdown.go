@@ -0,0 +1,404 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	htmlBlockStartRegex = regexp.MustCompile(
+		`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|img|section|!--)`,
+	)
+	htmlParagraphRegex = regexp.MustCompile(`\n[ \t]*\n`)
+	htmlWhitespace     = regexp.MustCompile(`\s+`)
+	htmlBlankLines     = regexp.MustCompile(`\n{3,}`)
+	htmlMarkdownEscape = strings.NewReplacer(
+		`\`, `\\`,
+		"`", "\\`",
+		`*`, `\*`,
+		`_`, `\_`,
+		`[`, `\[`,
+		`]`, `\]`,
+	)
+)
+
+// HTMLToMarkdown converts the HTML of a blog post, like the content of a
+// WordPress export, to markdown. WordPress stores posts with bare line breaks
+// instead of paragraphs, so those are turned into paragraphs first. Elements
+// without a markdown equivalent keep their text and lose their markup.
+func HTMLToMarkdown(src string) string {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := html.ParseFragment(strings.NewReader(autoParagraph(src)), context)
+	if err != nil {
+		return strings.TrimSpace(src)
+	}
+
+	md := blockMarkdown(nodes)
+	md = htmlBlankLines.ReplaceAllString(md, "\n\n")
+
+	return strings.TrimSpace(md) + "\n"
+}
+
+func autoParagraph(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	if strings.Contains(strings.ToLower(src), "<p") {
+		return src
+	}
+
+	chunks := htmlParagraphRegex.Split(src, -1)
+	for i, chunk := range chunks {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" || htmlBlockStartRegex.MatchString(chunk) {
+			chunks[i] = chunk
+			continue
+		}
+
+		chunks[i] = fmt.Sprintf("<p>%s</p>", strings.ReplaceAll(chunk, "\n", "<br>\n"))
+	}
+
+	return strings.Join(chunks, "\n\n")
+}
+
+func blockMarkdown(nodes []*html.Node) string {
+	blocks := []string{}
+	inline := ""
+
+	flush := func() {
+		if text := strings.TrimSpace(inline); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline = ""
+	}
+
+	for _, n := range nodes {
+		if !isBlockNode(n) {
+			inline += inlineMarkdown(n)
+			continue
+		}
+
+		flush()
+		if block := nodeMarkdown(n); strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	flush()
+
+	return strings.Join(blocks, "\n\n")
+}
+
+func nodeMarkdown(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		return fmt.Sprintf("%s %s", strings.Repeat("#", level), inlineChildren(n))
+	case atom.P, atom.Figcaption:
+		return inlineChildren(n)
+	case atom.Hr:
+		return "---"
+	case atom.Pre:
+		return codeBlock(n)
+	case atom.Blockquote:
+		return prefixLines(blockMarkdown(htmlChildren(n)), "> ", "> ")
+	case atom.Ul, atom.Ol:
+		return listMarkdown(n)
+	case atom.Table:
+		return tableMarkdown(n)
+	case atom.Script, atom.Style:
+		return ""
+	default:
+		return blockMarkdown(htmlChildren(n))
+	}
+}
+
+func codeBlock(n *html.Node) string {
+	lang := ""
+	code := n
+	if c := n.FirstChild; c != nil && c.DataAtom == atom.Code && c.NextSibling == nil {
+		code = c
+	}
+
+	for _, node := range []*html.Node{code, n} {
+		for _, class := range strings.Fields(htmlAttr(node, "class")) {
+			if l, ok := strings.CutPrefix(class, "language-"); ok && lang == "" {
+				lang = l
+			}
+		}
+	}
+
+	text := strings.TrimRight(htmlText(code), "\n")
+
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%s%s\n%s\n%s", fence, lang, text, fence)
+}
+
+func listMarkdown(n *html.Node) string {
+	items := []string{}
+	number := 1
+
+	for _, c := range htmlChildren(n) {
+		if c.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, prefixLines(blockMarkdown(htmlChildren(c)), marker, indent))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func tableMarkdown(n *html.Node) string {
+	rows := [][]string{}
+
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for _, c := range htmlChildren(node) {
+			if c.DataAtom != atom.Tr {
+				walk(c)
+				continue
+			}
+
+			cells := []string{}
+			for _, cell := range htmlChildren(c) {
+				if cell.DataAtom == atom.Td || cell.DataAtom == atom.Th {
+					text := strings.ReplaceAll(inlineChildren(cell), "|", `\|`)
+					cells = append(cells, strings.ReplaceAll(text, "\n", " "))
+				}
+			}
+
+			rows = append(rows, cells)
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+
+	lines := []string{}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+
+		lines = append(lines, fmt.Sprintf("| %s |", strings.Join(row, " | ")))
+
+		if i == 0 {
+			lines = append(lines, fmt.Sprintf("|%s", strings.Repeat(" --- |", columns)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func inlineChildren(n *html.Node) string {
+	sb := strings.Builder{}
+	for _, c := range htmlChildren(n) {
+		sb.WriteString(inlineMarkdown(c))
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(sb.String()), "\\")
+}
+
+func inlineMarkdown(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := htmlWhitespace.ReplaceAllString(n.Data, " ")
+		if p := n.PrevSibling; p != nil && p.DataAtom == atom.Br {
+			text = strings.TrimLeft(text, " ")
+		}
+
+		return htmlMarkdownEscape.Replace(text)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\\\n"
+	case atom.Strong, atom.B:
+		return wrapInline(n, "**")
+	case atom.Em, atom.I:
+		return wrapInline(n, "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(n, "~~")
+	case atom.Code:
+		text := htmlText(n)
+		fence := "`"
+		for strings.Contains(text, fence) {
+			fence += "`"
+		}
+
+		return fmt.Sprintf("%s%s%s", fence, text, fence)
+	case atom.A:
+		text := inlineChildren(n)
+		href := htmlAttr(n, "href")
+		if href == "" {
+			return text
+		}
+
+		return fmt.Sprintf(
+			"[%s](%s%s)",
+			text,
+			MarkdownURL(href),
+			markdownTitle(htmlAttr(n, "title")),
+		)
+	case atom.Img:
+		return fmt.Sprintf(
+			"![%s](%s%s)",
+			htmlMarkdownEscape.Replace(htmlAttr(n, "alt")),
+			MarkdownURL(htmlAttr(n, "src")),
+			markdownTitle(htmlAttr(n, "title")),
+		)
+	case atom.Script, atom.Style:
+		return ""
+	default:
+		sb := strings.Builder{}
+		for _, c := range htmlChildren(n) {
+			sb.WriteString(inlineMarkdown(c))
+		}
+
+		return sb.String()
+	}
+}
+
+// wrapInline keeps the surrounding spaces outside the delimiters, since
+// "** bold **" isn't emphasis in markdown.
+func wrapInline(n *html.Node, delimiter string) string {
+	sb := strings.Builder{}
+	for _, c := range htmlChildren(n) {
+		sb.WriteString(inlineMarkdown(c))
+	}
+
+	raw := sb.String()
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return raw
+	}
+
+	leading, trailing := "", ""
+	if strings.HasPrefix(raw, " ") {
+		leading = " "
+	}
+	if strings.HasSuffix(raw, " ") {
+		trailing = " "
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s", leading, delimiter, text, delimiter, trailing)
+}
+
+// MarkdownURL wraps URLs that would end a markdown link early in angle
+// brackets.
+func MarkdownURL(u string) string {
+	if strings.ContainsAny(u, " ()") {
+		return fmt.Sprintf("<%s>", u)
+	}
+
+	return u
+}
+
+func markdownTitle(title string) string {
+	if title == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" \"%s\"", strings.ReplaceAll(title, `"`, `\"`))
+}
+
+func prefixLines(text string, first string, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+
+		lines[i] = prefix + line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isBlockNode(n *html.Node) bool {
+	if n.Type == html.CommentNode {
+		return true
+	}
+
+	if n.Type != html.ElementNode {
+		return false
+	}
+
+	switch n.DataAtom {
+	case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Blockquote, atom.Pre, atom.Table, atom.Figure,
+		atom.Figcaption, atom.Hr, atom.Section, atom.Article, atom.Header,
+		atom.Footer, atom.Main, atom.Aside, atom.Script, atom.Style:
+		return true
+	}
+
+	return false
+}
+
+func htmlChildren(n *html.Node) []*html.Node {
+	nodes := []*html.Node{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		nodes = append(nodes, c)
+	}
+
+	return nodes
+}
+
+func htmlText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	sb := strings.Builder{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.DataAtom == atom.Br {
+			sb.WriteString("\n")
+			continue
+		}
+
+		sb.WriteString(htmlText(c))
+	}
+
+	return sb.String()
+}
+
+func htmlAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestHTMLToMarkdownParagraphs(t *testing.T) {
+	md := HTMLToMarkdown("First line\nsecond line\n\nAnother <strong>bold </strong>paragraph")
+
+	expected := "First line\\\nsecond line\n\nAnother **bold** paragraph\n"
+	if md != expected {
+		t.Errorf("Unexpected markdown, expected:\n%q\nreceived:\n%q", expected, md)
+	}
+}
+
+func TestHTMLToMarkdownBlocks(t *testing.T) {
+	md := HTMLToMarkdown(
+		"<h2>Title</h2><p>See <a href=\"https://example.com\" title=\"Ex\">this</a> " +
+			"and <em>that</em>.</p>" +
+			"<ul><li>one</li><li>two <code>x</code></li></ul>" +
+			"<ol><li>first</li><li>second</li></ol>" +
+			"<blockquote><p>quoted</p></blockquote>" +
+			"<pre><code class=\"language-go\">fmt.Println(\"hi\")\n</code></pre>" +
+			"<p><img src=\"/a b.png\" alt=\"pic\"></p>",
+	)
+
+	expected := "## Title\n\n" +
+		"See [this](https://example.com \"Ex\") and *that*.\n\n" +
+		"- one\n- two `x`\n\n" +
+		"1. first\n2. second\n\n" +
+		"> quoted\n\n" +
+		"```go\nfmt.Println(\"hi\")\n```\n\n" +
+		"![pic](</a b.png>)\n"
+	if md != expected {
+		t.Errorf("Unexpected markdown, expected:\n%s\nreceived:\n%s", expected, md)
+	}
+}
+
+func TestHTMLToMarkdownEscapesText(t *testing.T) {
+	md := HTMLToMarkdown("<p>2 * 3 = snake_case [x]</p><script>alert(1)</script>")
+
+	expected := "2 \\* 3 = snake\\_case \\[x\\]\n"
+	if md != expected {
+		t.Errorf("Unexpected markdown, expected:\n%q\nreceived:\n%q", expected, md)
+	}
+}
+
+func TestHTMLToMarkdownTable(t *testing.T) {
+	md := HTMLToMarkdown(
+		"<table><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>x|y</td></tr></table>",
+	)
+
+	expected := "| a | b |\n| --- | --- |\n| 1 | x\\|y |\n"
+	if md != expected {
+		t.Errorf("Unexpected markdown, expected:\n%q\nreceived:\n%q", expected, md)
+	}
+}
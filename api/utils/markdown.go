@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+type TocEntry struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(
+			extension.NewTable(
+				extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute),
+			),
+			extension.Strikethrough,
+			extension.Linkify,
+			extension.TaskList,
+			extension.Footnote,
+		),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	markdownPolicy = newMarkdownPolicy()
+)
+
+// RenderMarkdown renders GitHub flavored markdown (tables, task lists,
+// footnotes, strikethrough and autolinks) to HTML and returns the headings of
+// the document as a table of contents. The HTML is sanitized after rendering,
+// so raw HTML in the source and unsafe URLs never reach the page.
+func RenderMarkdown(src string) (string, []TocEntry) {
+	source := []byte(src)
+	ids := &headingIDs{used: map[string]int{}}
+
+	doc := markdown.Parser().Parse(text.NewReader(source))
+
+	toc := []TocEntry{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		if _, ok := n.(*extast.TaskCheckBox); ok {
+			if item := n.Parent().Parent(); item.Kind() == ast.KindListItem {
+				item.SetAttributeString("class", []byte("task-list-item"))
+			}
+			return ast.WalkContinue, nil
+		}
+
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		entry := TocEntry{Level: heading.Level, Text: headingText(heading, source)}
+		entry.Anchor = ids.next(entry.Text)
+		heading.SetAttributeString("id", []byte(entry.Anchor))
+
+		toc = append(toc, entry)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, source, doc); err != nil {
+		return "", toc
+	}
+
+	return markdownPolicy.Sanitize(buf.String()), toc
+}
+
+func newMarkdownPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_:-]+$`)).Globally()
+	p.AllowAttrs("class").
+		Matching(regexp.MustCompile(`^(footnote-ref|footnote-backref|footnotes)$`)).
+		OnElements("a", "div")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^task-list-item$`)).OnElements("li")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).
+		OnElements("th", "td")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+
+	return p
+}
+
+func headingText(heading *ast.Heading, source []byte) string {
+	var sb strings.Builder
+
+	ast.Walk(heading, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch t := n.(type) {
+		case *ast.Text:
+			sb.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(sb.String())
+}
+
+// headingIDs gives every heading an anchor made of its lowercased words, and
+// suffixes repeated anchors with -1, -2, ... so they stay unique. Goldmark's
+// own IDs drop every non-ASCII letter, which would leave most headings that
+// aren't written in English without an anchor.
+type headingIDs struct {
+	used map[string]int
+}
+
+func (ids *headingIDs) next(text string) string {
+	anchor := headingAnchor(text)
+	if anchor == "" {
+		anchor = "heading"
+	}
+
+	n, ok := ids.used[anchor]
+	ids.used[anchor] = n + 1
+	if !ok {
+		return anchor
+	}
+
+	return fmt.Sprintf("%s-%d", anchor, n)
+}
+
+func headingAnchor(text string) string {
+	var sb strings.Builder
+
+	for _, c := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_':
+			sb.WriteRune(c)
+		case unicode.IsSpace(c):
+			sb.WriteRune('-')
+		}
+	}
+
+	return sb.String()
+}
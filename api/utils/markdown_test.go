@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderMarkdownEscapesRawHTML(t *testing.T) {
+	html, _ := RenderMarkdown(
+		"<script>alert(1)</script>\n\n[x](javascript:alert) ![y](data:image/png) " +
+			"<img src=x onerror=alert(1)>",
+	)
+
+	for _, unsafe := range []string{"<script", "<img src=x", "javascript:", "data:"} {
+		if strings.Contains(html, unsafe) {
+			t.Errorf("Expected %q to be removed from %q", unsafe, html)
+		}
+	}
+}
+
+func TestRenderMarkdownTable(t *testing.T) {
+	html, _ := RenderMarkdown("| a | b |\n|:-:|--:|\n| 1 | **2** |\n")
+
+	expected := "<table>\n<thead>\n<tr>\n" +
+		"<th align=\"center\">a</th>\n<th align=\"right\">b</th>\n" +
+		"</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"center\">1</td>\n" +
+		"<td align=\"right\"><strong>2</strong></td>\n</tr>\n</tbody>\n</table>\n"
+
+	if html != expected {
+		t.Errorf("Unexpected table, expected:\n%s\nreceived:\n%s", expected, html)
+	}
+}
+
+func TestRenderMarkdownTaskList(t *testing.T) {
+	html, _ := RenderMarkdown("- [ ] todo\n- [x] done\n")
+
+	expected := "<ul>\n" +
+		"<li class=\"task-list-item\"><input disabled=\"\" type=\"checkbox\"> todo</li>\n" +
+		"<li class=\"task-list-item\">" +
+		"<input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n" +
+		"</ul>\n"
+
+	if html != expected {
+		t.Errorf("Unexpected task list, expected:\n%s\nreceived:\n%s", expected, html)
+	}
+}
+
+func TestRenderMarkdownFootnotes(t *testing.T) {
+	html, _ := RenderMarkdown("Text[^note].\n\n[^note]: The *note*.\n")
+
+	reference := "<sup id=\"fnref:1\"><a href=\"#fn:1\" class=\"footnote-ref\""
+	if !strings.Contains(html, reference) {
+		t.Errorf("Expected a footnote reference in %q", html)
+	}
+
+	if !strings.Contains(html, "<li id=\"fn:1\">\n<p>The <em>note</em>.") {
+		t.Errorf("Expected a footnote definition in %q", html)
+	}
+}
+
+func TestRenderMarkdownTableOfContents(t *testing.T) {
+	html, toc := RenderMarkdown("# Intro\n\n## Set `up`\n\n## Intro\n")
+
+	expected := []TocEntry{
+		{Level: 1, Text: "Intro", Anchor: "intro"},
+		{Level: 2, Text: "Set up", Anchor: "set-up"},
+		{Level: 2, Text: "Intro", Anchor: "intro-1"},
+	}
+
+	if len(toc) != len(expected) {
+		t.Fatalf("Expected %d entries, received %v", len(expected), toc)
+	}
+
+	for i := range expected {
+		if toc[i] != expected[i] {
+			t.Errorf("Expected entry %v at %d, received %v", expected[i], i, toc[i])
+		}
+
+		if !strings.Contains(html, "id=\""+expected[i].Anchor+"\"") {
+			t.Errorf("Expected a heading with anchor %s in %q", expected[i].Anchor, html)
+		}
+	}
+}
+
+func TestRenderMarkdownUnclosedLinks(t *testing.T) {
+	src := strings.Repeat("[a](b ", 20000) + strings.Repeat("![", 20000)
+
+	start := time.Now()
+	RenderMarkdown(src)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected unclosed links to render in linear time, took %s", elapsed)
+	}
+}
@@ -1,20 +1,39 @@
 package utils
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/unicode/norm"
 )
 
-var Validator = validator.New()
+var (
+	Validator     = newValidator()
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// newValidator registers the rules shared by several payloads. Usernames end
+// up in profile URLs, so they are limited to letters, digits, '_' and '-'.
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernameRegex.MatchString(fl.Field().String())
+	})
+
+	return v
+}
 
 func ParseJSONFromRequest(r *http.Request, payload any) error {
 	body := r.Body
@@ -48,18 +67,68 @@ func WriteErrorInResponse(w http.ResponseWriter, status int, message string) err
 	return WriteJSONInResponse(w, status, map[string]string{"message": message}, nil)
 }
 
+var slugTransliterations = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'ø': "o",
+	'đ': "d",
+	'ð': "d",
+	'ł': "l",
+	'þ': "th",
+	'ı': "i",
+}
+
+const maxSlugLength = 200
+
+// CreateSlug lowercases the title and joins its words with dashes. Accents are
+// removed from latin letters, while letters and digits of other scripts are
+// kept as they are, so titles in any language produce a readable slug.
 func CreateSlug(title string) string {
-	slug := strings.ToLower(title)
-	slug = strings.ReplaceAll(slug, " ", "-")
+	var slug strings.Builder
+
+	dash := false
+	latin := false
+	length := 0
 
-	slug = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
-			return r
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		if length >= maxSlugLength {
+			break
 		}
-		return -1
-	}, slug)
 
-	return slug
+		switch {
+		case r == '\'' || r == '’':
+			continue
+		case unicode.Is(unicode.Mn, r):
+			if !latin {
+				slug.WriteRune(r)
+			}
+			continue
+		case slugTransliterations[r] != "":
+			if dash {
+				slug.WriteByte('-')
+				dash = false
+			}
+
+			slug.WriteString(slugTransliterations[r])
+			length++
+			latin = true
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash {
+				slug.WriteByte('-')
+				dash = false
+			}
+
+			slug.WriteRune(r)
+			length++
+			latin = r < unicode.MaxLatin1 || unicode.Is(unicode.Latin, r)
+		case unicode.IsSpace(r) || r == '-' || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			dash = slug.Len() > 0
+			latin = false
+		}
+	}
+
+	return norm.NFC.String(slug.String())
 }
 
 func FileUploadHandler(
@@ -72,134 +141,187 @@ func FileUploadHandler(
 		w http.ResponseWriter,
 		r *http.Request,
 	) {
-		maxSizeInBytes := maxSizeInMB * 1024 * 1024
-
-		r.Body = http.MaxBytesReader(w, r.Body, maxSizeInBytes)
-		if err := r.ParseMultipartForm(maxSizeInBytes); err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf(
-					"The uploaded file is too big. Please choose an file that's less than %dMB in size",
-					maxSizeInMB,
-				),
-			)
+		filename, ok := SaveUploadedFile(w, r, field, maxSizeInMB, mimeTypes, directory)
+		if !ok {
 			return
 		}
 
-		file, handler, err := r.FormFile(field)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Cannot retrieve the file: %v", err),
-			)
-			return
-		}
-		defer file.Close()
-
-		buf := make([]byte, 512)
-		_, err = file.Read(buf)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Error in file uploading: %v", err),
-			)
-			return
-		}
+		WriteJSONInResponse(w, http.StatusOK, map[string]string{
+			"message": fmt.Sprintf(
+				"File with the name '%s' has been uploaded successfully",
+				filename,
+			),
+		}, nil)
+	}
+}
 
-		mimeTypeFromHandler := handler.Header.Get("Content-Type")
-		mimeTypeFromMTLib := mimetype.Detect(buf).String()
+// SaveUploadedFile stores the multipart file in the given field under the
+// directory and returns its new name. On failure the error is already written
+// to the response and false is returned.
+func SaveUploadedFile(
+	w http.ResponseWriter,
+	r *http.Request,
+	field string,
+	maxSizeInMB int64,
+	mimeTypes []string,
+	directory string,
+) (string, bool) {
+	if !ParseMultipartRequest(w, r, maxSizeInMB) {
+		return "", false
+	}
 
-		typeFound := false
+	return SaveFormFile(w, r, field, maxSizeInMB, mimeTypes, directory)
+}
 
-		for i := range mimeTypes {
-			m := mimeTypes[i]
+func ParseMultipartRequest(w http.ResponseWriter, r *http.Request, maxSizeInMB int64) bool {
+	maxSizeInBytes := maxSizeInMB * 1024 * 1024
 
-			if mimeTypeFromHandler == m || mimeTypeFromMTLib == m {
-				typeFound = true
-			}
-		}
+	r.Body = http.MaxBytesReader(w, r.Body, maxSizeInBytes)
+	if err := r.ParseMultipartForm(maxSizeInBytes); err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf(
+				"The uploaded file is too big. Please choose an file that's less than %dMB in size",
+				maxSizeInMB,
+			),
+		)
+		return false
+	}
 
-		if !typeFound {
-			allowedMimeTypesString := strings.Join(mimeTypes, " , ")
-			errMsg := ""
-
-			if mimeTypeFromHandler == mimeTypeFromMTLib {
-				errMsg = fmt.Sprintf(
-					"Cannot upload %s file, please upload only %s files",
-					mimeTypeFromHandler,
-					allowedMimeTypesString,
-				)
-			} else {
-				errMsg = fmt.Sprintf(
-					"Cannot upload %s/%s file, please upload only %s files",
-					mimeTypeFromHandler,
-					mimeTypeFromMTLib,
-					allowedMimeTypesString,
-				)
-			}
+	return true
+}
 
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				errMsg,
-			)
-			return
-		}
+// SaveFormFile is SaveUploadedFile for a request whose multipart form has
+// already been parsed. The size of the file is checked on its own, since the
+// form may carry other files that share the limit of the whole request.
+func SaveFormFile(
+	w http.ResponseWriter,
+	r *http.Request,
+	field string,
+	maxSizeInMB int64,
+	mimeTypes []string,
+	directory string,
+) (string, bool) {
+	file, handler, err := r.FormFile(field)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Cannot retrieve the file: %v", err),
+		)
+		return "", false
+	}
+	defer file.Close()
 
-		_, err = file.Seek(0, io.SeekStart)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Error in file uploading: %v", err),
-			)
-			return
-		}
+	if handler.Size > maxSizeInMB*1024*1024 {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf(
+				"The uploaded file is too big. Please choose an file that's less than %dMB in size",
+				maxSizeInMB,
+			),
+		)
+		return "", false
+	}
 
-		err = os.MkdirAll(directory, os.ModePerm)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Error in file uploading: %v", err),
-			)
-			return
+	buf := make([]byte, 512)
+	_, err = file.Read(buf)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Error in file uploading: %v", err),
+		)
+		return "", false
+	}
+
+	mimeTypeFromHandler := handler.Header.Get("Content-Type")
+	mimeTypeFromMTLib := mimetype.Detect(buf).String()
+
+	typeFound := false
+
+	for i := range mimeTypes {
+		m := mimeTypes[i]
+
+		if mimeTypeFromHandler == m || mimeTypeFromMTLib == m {
+			typeFound = true
 		}
+	}
 
-		filename := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(handler.Filename))
-		fullpath := fmt.Sprintf("%s/%s", directory, filename)
+	if !typeFound {
+		allowedMimeTypesString := strings.Join(mimeTypes, " , ")
+		errMsg := ""
 
-		dest, err := os.Create(fullpath)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Error in file uploading: %v", err),
+		if mimeTypeFromHandler == mimeTypeFromMTLib {
+			errMsg = fmt.Sprintf(
+				"Cannot upload %s file, please upload only %s files",
+				mimeTypeFromHandler,
+				allowedMimeTypesString,
 			)
-			return
-		}
-		defer dest.Close()
-
-		_, err = io.Copy(dest, file)
-		if err != nil {
-			WriteErrorInResponse(
-				w,
-				http.StatusBadRequest,
-				fmt.Sprintf("Error in file uploading: %v", err),
+		} else {
+			errMsg = fmt.Sprintf(
+				"Cannot upload %s/%s file, please upload only %s files",
+				mimeTypeFromHandler,
+				mimeTypeFromMTLib,
+				allowedMimeTypesString,
 			)
-			return
 		}
 
-		WriteJSONInResponse(w, http.StatusOK, map[string]string{
-			"message": fmt.Sprintf(
-				"File with the name '%s' has been uploaded successfully",
-				filename,
-			),
-		}, nil)
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			errMsg,
+		)
+		return "", false
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Error in file uploading: %v", err),
+		)
+		return "", false
+	}
+
+	err = os.MkdirAll(directory, os.ModePerm)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Error in file uploading: %v", err),
+		)
+		return "", false
 	}
+
+	filename := fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(handler.Filename))
+	fullpath := fmt.Sprintf("%s/%s", directory, filename)
+
+	dest, err := os.Create(fullpath)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Error in file uploading: %v", err),
+		)
+		return "", false
+	}
+	defer dest.Close()
+
+	_, err = io.Copy(dest, file)
+	if err != nil {
+		WriteErrorInResponse(
+			w,
+			http.StatusBadRequest,
+			fmt.Sprintf("Error in file uploading: %v", err),
+		)
+		return "", false
+	}
+
+	return filename, true
 }
 
 func PathExists(path string) (bool, error) {
@@ -212,3 +334,31 @@ func PathExists(path string) (bool, error) {
 	}
 	return false, err
 }
+
+type Cursor struct {
+	Value string `json:"v"`
+	Id    string `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid cursor")
+	}
+
+	c := new(Cursor)
+	if err := json.Unmarshal(b, c); err != nil || c.Id == "" {
+		return nil, fmt.Errorf("Invalid cursor")
+	}
+
+	return c, nil
+}
@@ -0,0 +1,24 @@
+package utils
+
+import "testing"
+
+func TestCreateSlug(t *testing.T) {
+	cases := map[string]string{
+		"My Test Blog":             "my-test-blog",
+		"  Hello,   World!  ":      "hello-world",
+		"Don't Panic":              "dont-panic",
+		"Crème Brûlée à la carte":  "creme-brulee-a-la-carte",
+		"Straße":                   "strasse",
+		"Привет мир":               "привет-мир",
+		"سلام دنیا":                "سلام-دنیا",
+		"안녕 세상":                    "안녕-세상",
+		"snake_case -- and dashes": "snake_case-and-dashes",
+		"🚀🚀":                       "",
+	}
+
+	for title, expected := range cases {
+		if slug := CreateSlug(title); slug != expected {
+			t.Errorf("Expected slug of %q to be %q, received %q", title, expected, slug)
+		}
+	}
+}